@@ -1,54 +1,151 @@
 package main
 
 import (
-	"log"
 	"net/http"
+	"os"
+	"time"
 
 	// All our application packages
 	"quizz-core/internal/api"
+	"quizz-core/internal/auth"
 	"quizz-core/internal/config"
 	"quizz-core/internal/llm"
-	"quizz-core/internal/service"
+	"quizz-core/internal/logging"
+	"quizz-core/internal/metrics"
+	"quizz-core/internal/repository/comment"
+	"quizz-core/internal/repository/paper"
+	"quizz-core/internal/repository/quiz"
+	"quizz-core/internal/repository/submission"
+	"quizz-core/internal/repository/theme"
+	"quizz-core/internal/repository/user"
 	"quizz-core/internal/store"
+	"quizz-core/internal/usecase"
+
+	"github.com/gin-gonic/gin"
 )
 
 func main() {
 	// 1. Load Configuration
 	cfg := config.LoadConfig()
 
+	// 1.1 Build o logger raiz (nível e formato vêm do Config)
+	logger := logging.New(cfg.LogLevel, cfg.Env)
+
+	// 1.2 O modo do Gin (debug/release) segue o mesmo APP_ENV que já
+	// controla o formato do logger.
+	if cfg.Env == "production" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
 	// 2. Run Database Migrations
-	store.RunMigrations(cfg.DBConnectionStringURL)
+	store.RunMigrations(cfg.DBConnectionStringURL, logger)
 
 	// --- DEPENDENCY INJECTION (Building all the pieces) ---
 
 	// 3. Build the Database Layer (Store)
-	db, err := store.NewPostgresStore(cfg.DBConnectionString)
+	db, err := store.NewPostgresStore(cfg.DBConnectionString, logger)
 	if err != nil {
-		log.Fatalf("Could not connect to database: %v", err)
+		logger.Error("não foi possível conectar ao banco de dados", "error", err)
+		os.Exit(1)
+	}
+
+	// 4. Build the LLM Provider (based on LLM_PROVIDER: ollama, openai ou mock)
+	var llmProvider llm.Provider
+	switch cfg.LLMProvider {
+	case "openai":
+		llmProvider = llm.NewOpenAIProvider(cfg.LLMEndpoint, cfg.LLMModel, cfg.LLMAPIKey)
+	case "mock":
+		llmProvider = llm.NewMockProvider(cfg.LLMEndpoint) // Aqui LLMEndpoint aponta para o caminho da fixture
+	default:
+		llmProvider = llm.NewOllamaProvider(cfg.LLMEndpoint, cfg.LLMModel)
+	}
+	// 4.1 Envolve o provider com retry/backoff + circuit breaker
+	llmProvider = llm.NewResilientProvider(llmProvider, 3, 500*time.Millisecond)
+
+	// 5. Build os Repositórios (um por entidade, cada um só sabe falar com o Postgres)
+	var questionProcessor store.QuestionContentProcessor = store.NoOpContentProcessor{}
+	if cfg.QuestionEncryptionKeys != "" {
+		keys, err := store.ParseQuestionEncryptionKeys(cfg.QuestionEncryptionKeys)
+		if err != nil {
+			logger.Error("chaves de encriptação de perguntas inválidas (QUESTION_ENCRYPTION_KEYS)", "error", err)
+			os.Exit(1)
+		}
+		activeID, err := store.ParseKeyID(cfg.QuestionEncryptionActiveKeyID)
+		if err != nil {
+			logger.Error("QUESTION_ENCRYPTION_ACTIVE_KEY_ID inválido", "value", cfg.QuestionEncryptionActiveKeyID, "error", err)
+			os.Exit(1)
+		}
+		questionProcessor, err = store.NewAesGcmProcessor(keys, activeID)
+		if err != nil {
+			logger.Error("falha ao inicializar encriptação de perguntas", "error", err)
+			os.Exit(1)
+		}
+		logger.Info("encriptação em repouso de perguntas ativada", "active_key_id", activeID)
 	}
 
-	// 4. Build the LLM Client
-	llmClient := llm.NewClient(cfg.LLMEndpoint, cfg.LLMModel)
-	// 5. Build the Service Layer (Injecting DB and LLM)
-	quizSvc := service.NewQuizService(db, llmClient)
+	quizRepo := quiz.NewPostgresRepository(db.DB, questionProcessor)
+	submissionRepo := submission.NewPostgresRepository(db.DB, questionProcessor)
+	themeRepo := theme.NewPostgresRepository(db.DB)
+	paperRepo := paper.NewPostgresRepository(db.DB, questionProcessor)
+	commentRepo := comment.NewPostgresRepository(db.DB)
+	userRepo := user.NewPostgresRepository(db.DB)
 
-	// 6. Build the API/Handlers Layer (Injecting the Service)
-	handlers := api.NewApiHandlers(quizSvc)
+	// 5.1 Build a Camada de Usecase (compõe os repositórios + LLM)
+	quizUsecase := usecase.NewQuizUsecase(quizRepo, submissionRepo, themeRepo, paperRepo, commentRepo, db, llmProvider)
+
+	// 6. Build the API/Handlers Layer (Injecting the Usecase)
+	handlers := api.NewApiHandlers(quizUsecase)
+
+	// 6.1 Build a sessão de cookie usada por LoginSessionHandler/RequireSessionGin
+	sessionStore := auth.NewSessionStore(cfg.SessionSecret)
+	cookieOpts := auth.CookieOptions{
+		Secure:   cfg.SessionCookieSecure,
+		HTTPOnly: cfg.SessionCookieHTTPOnly,
+		SameSite: auth.ParseSameSite(cfg.SessionCookieSameSite),
+	}
+
+	// 6.2 Build the Auth Layer (register/login + middleware)
+	authHandlers := auth.NewHandlers(userRepo, cfg.JWTSecret, cfg.JWTTokenTTL, sessionStore, cookieOpts)
+	requireAuth := auth.RequireAuthGin(cfg.JWTSecret)
+	requireAdmin := auth.RequireAdminGin(cfg.JWTSecret)
 
 	// --- End of Dependency Injection ---
 
-	// 7. Configure Routes (connecting URLs to the handler methods)
-	mux := http.NewServeMux()
-	mux.HandleFunc("/health", handlers.HealthCheckHandler)
-	mux.HandleFunc("/api/v1/quiz/create", handlers.CreateQuizHandler) // <-- OUR NEW ENDPOINT
-	// (Future) mux.HandleFunc("/api/v1/quiz/submit", handlers.SubmitAnswersHandler)
+	// 7. Configure Routes: api.NewRouter monta o engine com o pipeline de
+	// middlewares transversais (request-id/logging, recuperação de pânico,
+	// CORS, sessão) e as rotas já nativas do Gin (/v1/...). As rotas que ainda
+	// não foram portadas continuam em net/http e são montadas aqui por cima do
+	// mesmo engine via gin.WrapH/gin.WrapF.
+	engine := api.NewRouter(handlers, cfg.JWTSecret, sessionStore, cookieOpts, logger, cfg.AccessLogFormat)
+
+	engine.GET("/health", gin.WrapF(handlers.HealthCheckHandler))
+	engine.GET("/metrics", gin.WrapH(metrics.Handler()))
+	engine.POST("/auth/register", gin.WrapH(http.HandlerFunc(authHandlers.RegisterHandler)))
+	// /auth/login passa a autenticar por sessão/cookie; o JWT original fica
+	// disponível em /auth/login/token para clientes/automação que preferem
+	// bearer tokens a cookies (o mesmo critério já usado para reservar
+	// GetSubmissionDetailsHandler e DeactivateQuizHandler a requireAuth em
+	// api.NewRouter).
+	engine.POST("/auth/login", authHandlers.LoginSessionHandler)
+	engine.POST("/auth/login/token", gin.WrapH(http.HandlerFunc(authHandlers.LoginHandler)))
+	engine.POST("/auth/logout", authHandlers.LogoutHandler)
+
+	// Endpoints que recebem um user_id passam a exigir um JWT válido.
+	engine.GET("/quizzes/stream", requireAuth, gin.WrapH(http.HandlerFunc(handlers.CreateQuizStreamHandler)))
+	engine.PATCH("/api/v1/quiz/draft", requireAuth, gin.WrapH(http.HandlerFunc(handlers.SaveDraftHandler)))
+	engine.Any("/api/v1/papers", requireAuth, gin.WrapH(http.HandlerFunc(handlers.PapersHandler)))
+	engine.POST("/api/v1/papers/instantiate", requireAuth, gin.WrapH(http.HandlerFunc(handlers.InstantiatePaperHandler)))
+
+	// Só admins podem criar contas para outros utilizadores
+	engine.POST("/api/v1/admin/users", requireAdmin, gin.WrapH(http.HandlerFunc(authHandlers.AdminCreateUserHandler)))
 
 	// 8. Start the Server
 	serverAddr := ":" + cfg.Port
-	log.Printf("Server running on http://localhost:%s\n", cfg.Port)
+	logger.Info("servidor a arrancar", "addr", "http://localhost:"+cfg.Port)
 
-	err = http.ListenAndServe(serverAddr, mux)
+	err = http.ListenAndServe(serverAddr, engine)
 	if err != nil {
-		log.Fatalf("Error starting server: %v", err)
+		logger.Error("falha ao arrancar o servidor", "error", err)
+		os.Exit(1)
 	}
 }
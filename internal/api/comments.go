@@ -0,0 +1,181 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"quizz-core/internal/apierr"
+	"quizz-core/internal/auth"
+	"quizz-core/internal/models"
+	"quizz-core/internal/usecase"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseCommentCursorPage lê os parâmetros de paginação por cursor (limit,
+// cursor) da query string de um endpoint de comentários. Valores inválidos
+// ou em branco ficam 0, que o usecase normaliza para os defaults (ver
+// store.NormalizeCursorLimit).
+func parseCommentCursorPage(r *http.Request) models.CommentCursorPage {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	cursor, _ := strconv.Atoi(r.URL.Query().Get("cursor"))
+	return models.CommentCursorPage{Limit: limit, Cursor: cursor}
+}
+
+// commentUsecaseErr mapeia os erros de negócio comuns às rotas de
+// comentários para o *apierr.APIError correspondente, incluindo
+// ErrForbidden (403), que as restantes rotas ainda não precisavam de
+// distinguir de ErrNotFound.
+func commentUsecaseErr(err error) *apierr.APIError {
+	switch {
+	case errors.Is(err, usecase.ErrNotFound):
+		return apierr.NotFound("Recurso não encontrado").WithCause(err)
+	case errors.Is(err, usecase.ErrInvalidInput):
+		return apierr.InvalidInput("Input inválido: " + err.Error())
+	case errors.Is(err, usecase.ErrForbidden):
+		return apierr.Forbidden("Acesso negado: só o autor do comentário ou um admin pode apagá-lo").WithCause(err)
+	default:
+		return apierr.Internal("Falha interna ao processar comentário").WithCause(err)
+	}
+}
+
+// ListQuizCommentsHandler lista os comentários de um quiz, paginados por
+// cursor. Rota nativa do Gin: GET /v1/quizzes/:quiz_id/comments.
+func (h *ApiHandlers) ListQuizCommentsHandler(c *gin.Context) error {
+	quizID := c.Param("quiz_id")
+
+	page, err := h.quizUsecase.ListQuizComments(c.Request.Context(), quizID, parseCommentCursorPage(c.Request))
+	if err != nil {
+		return commentUsecaseErr(err)
+	}
+	c.JSON(http.StatusOK, page)
+	return nil
+}
+
+// CreateQuizCommentHandler cria um comentário num quiz. UtilizadorID não vem
+// do corpo: o handler preenche-o a partir do subject autenticado, tal como
+// em CreateQuizHandler. Rota nativa do Gin: POST /v1/quizzes/:quiz_id/comments.
+func (h *ApiHandlers) CreateQuizCommentHandler(c *gin.Context) error {
+	quizID := c.Param("quiz_id")
+
+	var req models.CreateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return apierr.InvalidInput("JSON inválido: " + err.Error())
+	}
+
+	authUserID, ok := auth.UserIDFromContext(c.Request.Context())
+	if !ok {
+		return apierr.Unauthorized("Não autorizado")
+	}
+	req.UtilizadorID = authUserID
+
+	created, err := h.quizUsecase.CreateQuizComment(c.Request.Context(), quizID, req)
+	if err != nil {
+		return commentUsecaseErr(err)
+	}
+	c.JSON(http.StatusCreated, created)
+	return nil
+}
+
+// DeleteQuizCommentHandler apaga um comentário de um quiz: só o autor ou um
+// admin pode fazê-lo (ver usecase.DeleteQuizComment). Rota nativa do Gin:
+// DELETE /v1/quizzes/:quiz_id/comments/:comment_id.
+func (h *ApiHandlers) DeleteQuizCommentHandler(c *gin.Context) error {
+	quizID := c.Param("quiz_id")
+	commentID := c.Param("comment_id")
+
+	authUserID, ok := auth.UserIDFromContext(c.Request.Context())
+	if !ok {
+		return apierr.Unauthorized("Não autorizado")
+	}
+	tipo, _ := auth.TipoFromContext(c.Request.Context())
+
+	if err := h.quizUsecase.DeleteQuizComment(c.Request.Context(), quizID, commentID, authUserID, tipo == "admin"); err != nil {
+		return commentUsecaseErr(err)
+	}
+	c.JSON(http.StatusOK, SimpleMessageResponse{Message: "Comentário apagado com sucesso"})
+	return nil
+}
+
+// ExportQuizCommentsHandler devolve todos os comentários de um quiz de uma
+// só vez, sem paginação, para revisão offline (ex: ferramentas de correção
+// de turmas). Reservado a admins, tal como DeactivateQuizHandler. Rota
+// nativa do Gin: GET /v1/quizzes/:quiz_id/comments/export.json.
+func (h *ApiHandlers) ExportQuizCommentsHandler(c *gin.Context) error {
+	quizID := c.Param("quiz_id")
+
+	comments, err := h.quizUsecase.ExportQuizComments(c.Request.Context(), quizID)
+	if err != nil {
+		return commentUsecaseErr(err)
+	}
+	c.JSON(http.StatusOK, comments)
+	return nil
+}
+
+// ListSubmissionCommentsHandler lista os comentários de uma submissão,
+// paginados por cursor. Só o dono da submissão ou um admin pode vê-los,
+// tal como em GetSubmissionDetailsHandler. Rota nativa do Gin:
+// GET /v1/submissions/:submission_id/comments.
+func (h *ApiHandlers) ListSubmissionCommentsHandler(c *gin.Context) error {
+	submissionID := c.Param("submission_id")
+
+	authUserID, ok := auth.UserIDFromContext(c.Request.Context())
+	if !ok {
+		return apierr.Unauthorized("Não autorizado")
+	}
+	tipo, _ := auth.TipoFromContext(c.Request.Context())
+
+	page, err := h.quizUsecase.ListSubmissionComments(c.Request.Context(), submissionID, parseCommentCursorPage(c.Request), authUserID, tipo == "admin")
+	if err != nil {
+		return commentUsecaseErr(err)
+	}
+	c.JSON(http.StatusOK, page)
+	return nil
+}
+
+// CreateSubmissionCommentHandler cria um comentário numa submissão. Só o
+// dono da submissão ou um admin pode comentar nela — ver
+// ListSubmissionCommentsHandler. Rota nativa do Gin:
+// POST /v1/submissions/:submission_id/comments.
+func (h *ApiHandlers) CreateSubmissionCommentHandler(c *gin.Context) error {
+	submissionID := c.Param("submission_id")
+
+	var req models.CreateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return apierr.InvalidInput("JSON inválido: " + err.Error())
+	}
+
+	authUserID, ok := auth.UserIDFromContext(c.Request.Context())
+	if !ok {
+		return apierr.Unauthorized("Não autorizado")
+	}
+	req.UtilizadorID = authUserID
+	tipo, _ := auth.TipoFromContext(c.Request.Context())
+
+	created, err := h.quizUsecase.CreateSubmissionComment(c.Request.Context(), submissionID, req, authUserID, tipo == "admin")
+	if err != nil {
+		return commentUsecaseErr(err)
+	}
+	c.JSON(http.StatusCreated, created)
+	return nil
+}
+
+// DeleteSubmissionCommentHandler apaga um comentário de uma submissão: só o
+// autor ou um admin pode fazê-lo. Rota nativa do Gin:
+// DELETE /v1/submissions/:submission_id/comments/:comment_id.
+func (h *ApiHandlers) DeleteSubmissionCommentHandler(c *gin.Context) error {
+	submissionID := c.Param("submission_id")
+	commentID := c.Param("comment_id")
+
+	authUserID, ok := auth.UserIDFromContext(c.Request.Context())
+	if !ok {
+		return apierr.Unauthorized("Não autorizado")
+	}
+	tipo, _ := auth.TipoFromContext(c.Request.Context())
+
+	if err := h.quizUsecase.DeleteSubmissionComment(c.Request.Context(), submissionID, commentID, authUserID, tipo == "admin"); err != nil {
+		return commentUsecaseErr(err)
+	}
+	c.JSON(http.StatusOK, SimpleMessageResponse{Message: "Comentário apagado com sucesso"})
+	return nil
+}
@@ -1,12 +1,23 @@
 package api
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
-	"log"
+	"fmt"
+	"io"
 	"net/http"
+	"quizz-core/internal/apierr"
+	"quizz-core/internal/auth"
+	"quizz-core/internal/llm"
+	"quizz-core/internal/logging"
 	"quizz-core/internal/models"
-	"quizz-core/internal/service" // Import o service
+	"quizz-core/internal/usecase"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
 )
 
 // SimpleMessageResponse é uma struct genérica para respostas de sucesso
@@ -14,21 +25,36 @@ type SimpleMessageResponse struct {
 	Message string `json:"message"`
 }
 
+// parsePagination lê os parâmetros comuns de paginação/busca/ordenação
+// (q, page, limit, sort) da query string de um endpoint de listagem.
+// page/limit inválidos ou em branco ficam 0, que o usecase/repositório
+// normaliza para os defaults (ver store.NormalizePagination).
+func parsePagination(r *http.Request) models.Pagination {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	return models.Pagination{
+		Page:  page,
+		Limit: limit,
+		Q:     r.URL.Query().Get("q"),
+		Sort:  r.URL.Query().Get("sort"),
+	}
+}
+
 // ApiHandlers is our struct that will hold dependencies
 type ApiHandlers struct {
-	quizService *service.QuizService
+	quizUsecase usecase.QuizUsecase
 }
 
 // NewApiHandlers is the constructor for our handlers
-func NewApiHandlers(qs *service.QuizService) *ApiHandlers {
+func NewApiHandlers(qu usecase.QuizUsecase) *ApiHandlers {
 	return &ApiHandlers{
-		quizService: qs,
+		quizUsecase: qu,
 	}
 }
 
 // HealthCheckHandler (agora verifica as dependências)
 func (h *ApiHandlers) HealthCheckHandler(w http.ResponseWriter, r *http.Request) {
-	healthStatus := h.quizService.CheckHealth(r.Context())
+	healthStatus := h.quizUsecase.CheckHealth(r.Context())
 	w.Header().Set("Content-Type", "application/json")
 	if healthStatus.Dependencies["database"] == models.StatusDown {
 		w.WriteHeader(http.StatusServiceUnavailable) // 503
@@ -36,65 +62,218 @@ func (h *ApiHandlers) HealthCheckHandler(w http.ResponseWriter, r *http.Request)
 		w.WriteHeader(http.StatusOK) // 200
 	}
 	if err := json.NewEncoder(w).Encode(healthStatus); err != nil {
-		log.Printf("Erro ao enviar resposta JSON de health check: %v", err)
+		logging.FromContext(r.Context()).Error("erro ao enviar resposta JSON de health check", "error", err)
 		http.Error(w, "Erro ao gerar health status", http.StatusInternalServerError)
 	}
 }
 
-// CreateQuizHandler agora retorna o JSON cru da LLM
-func (h *ApiHandlers) CreateQuizHandler(w http.ResponseWriter, r *http.Request) {
-	// 1. Only accept POST
-	if r.Method != http.MethodPost {
-		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
-		return
+// CreateQuizHandler agora retorna o JSON cru da LLM. Rota nativa do Gin:
+// POST /v1/quizzes.
+func (h *ApiHandlers) CreateQuizHandler(c *gin.Context) error {
+	// 1. Decode JSON
+	var req models.CreateQuizRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return apierr.InvalidInput("JSON inválido: " + err.Error())
 	}
 
-	// 2. Decode JSON
-	var req models.CreateQuizRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "JSON inválido: "+err.Error(), http.StatusBadRequest)
-		return
+	// 2. Validação de Input: ou um theme para a LLM gerar, ou um paper_id
+	// de um quiz já montado a partir de perguntas existentes.
+	if req.Theme == "" && req.PaperID == "" {
+		return apierr.InvalidInput("Input inválido: 'theme' ou 'paper_id' é obrigatório")
+	}
+
+	// 2.1 UserID não vem do body (json:"-"): preenchemo-lo sempre a partir
+	// do subject autenticado no token.
+	authUserID, ok := auth.UserIDFromContext(c.Request.Context())
+	if !ok {
+		return apierr.Unauthorized("Não autorizado")
+	}
+	req.UserID = strconv.Itoa(authUserID)
+
+	// 3. Call the Service
+	// Agora recebe RawQuizResponse em vez de QuizAPIResponse
+	rawQuizResponse, err := h.quizUsecase.CreateQuiz(c.Request.Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrNotFound):
+			return apierr.NotFound("Recurso não encontrado (ex: paper_id inválido)").WithCause(err)
+		case errors.Is(err, usecase.ErrInvalidInput):
+			return apierr.InvalidInput("Input inválido: " + err.Error())
+		case errors.Is(err, usecase.ErrUnavailable):
+			return apierr.Unavailable("Serviço de geração de quiz temporariamente indisponível").WithCause(err)
+		default:
+			return apierr.Internal("Falha ao gerar quiz").WithCause(err)
+		}
 	}
-	defer r.Body.Close()
 
-	// 3. Validação de Input
-	if req.UserID == "" {
-		http.Error(w, "Input inválido: 'user_id' não pode estar em branco", http.StatusBadRequest)
+	// 4. Send the raw JSON response back.
+	// Usamos 200 OK porque não criámos um recurso persistente *neste serviço*
+	c.JSON(http.StatusOK, rawQuizResponse)
+	return nil
+}
+
+// CreateQuizStreamHandler gera o quiz em modo streaming e vai emitindo cada
+// pergunta assim que fica pronta via Server-Sent Events (event: question),
+// em vez de obrigar o cliente a esperar o quiz inteiro de uma só vez.
+// Termina com event: done, ou event: error em caso de falha. Enquanto
+// espera, emite um heartbeat (comentário SSE) a cada 15s para a ligação não
+// ser fechada por proxies/load balancers. O endpoint síncrono original,
+// CreateQuizHandler, continua disponível para clientes que preferem
+// receber o quiz completo de uma vez.
+func (h *ApiHandlers) CreateQuizStreamHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
 		return
 	}
+
+	var req models.CreateQuizRequest
+	req.Theme = r.URL.Query().Get("theme")
 	if req.Theme == "" {
 		http.Error(w, "Input inválido: 'theme' não pode estar em branco", http.StatusBadRequest)
 		return
 	}
 
-	// 4. Call the Service
-	// Agora recebe RawQuizResponse em vez de QuizAPIResponse
-	rawQuizResponse, err := h.quizService.CreateQuiz(r.Context(), req)
-	if err != nil {
-		log.Printf("Error creating quiz raw: %v", err)
-		http.Error(w, "Falha ao gerar quiz", http.StatusInternalServerError)
+	authUserID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Não autorizado", http.StatusUnauthorized)
 		return
 	}
+	req.UserID = strconv.Itoa(authUserID)
 
-	// 5. Send the raw JSON response back
-	w.Header().Set("Content-Type", "application/json")
-	// Usamos 200 OK porque não criámos um recurso persistente *neste serviço*
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming não suportado por este servidor", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
 	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(rawQuizResponse); err != nil {
-		log.Printf("Erro ao enviar resposta JSON crua: %v", err)
+
+	chunks, errs := h.quizUsecase.CreateQuizStream(r.Context(), req)
+
+	// heartbeat mantém a ligação viva através de proxies/load balancers que
+	// cortam conexões HTTP ociosas (o LLM pode demorar bastante entre
+	// perguntas). É um comentário SSE (linha começada por ':'), que o
+	// EventSource do cliente ignora silenciosamente em vez de o tratar como
+	// um evento.
+	heartbeat := time.NewTicker(15 * time.Second)
+	defer heartbeat.Stop()
+
+	for chunks != nil || errs != nil {
+		select {
+		case chunk, open := <-chunks:
+			if !open {
+				chunks = nil
+				continue
+			}
+			if chunk.Done {
+				fmt.Fprintf(w, "event: done\ndata: {}\n\n")
+				flusher.Flush()
+				continue
+			}
+			payload := chunk.Wrapper
+			if payload == nil && chunk.Question != nil {
+				payload = &llm.LLMWrapper{Questions: []llm.LLMQuestionResponse{*chunk.Question}}
+			}
+			if payload == nil {
+				continue
+			}
+			data, err := json.Marshal(payload)
+			if err != nil {
+				logging.FromContext(r.Context()).Error("erro ao serializar chunk de quiz em streaming", "error", err)
+				continue
+			}
+			fmt.Fprintf(w, "event: question\ndata: %s\n\n", data)
+			flusher.Flush()
+		case err, open := <-errs:
+			if !open {
+				errs = nil
+				continue
+			}
+			if err != nil {
+				logging.FromContext(r.Context()).Error("erro em CreateQuizStream", "error", err)
+				fmt.Fprintf(w, "event: error\ndata: {\"message\": %q}\n\n", err.Error())
+				flusher.Flush()
+			}
+		case <-heartbeat.C:
+			fmt.Fprintf(w, ": heartbeat\n\n")
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
 	}
 }
 
-// --- OS HANDLERS ABAIXO NÃO MUDAM ---
-// (SubmitAnswersHandler, GetUserStatsHandler, GetUserSubmissionsHandler, GetAllThemesHandler, DeactivateQuizHandler, GetSubmissionDetailsHandler, GetQuizzesByThemeHandler)
+// --- OS HANDLERS ABAIXO (SaveDraftHandler, PapersHandler e o CRUD de papers,
+// InstantiatePaperHandler) NÃO MUDAM: continuam em net/http e são montados no
+// gin.Engine via gin.WrapH (ver NewRouter em router.go) ---
 
-// SubmitAnswersHandler é o handler para receber as respostas do quiz
-func (h *ApiHandlers) SubmitAnswersHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
-		return
+// SubmitAnswersHandler é o handler para receber as respostas do quiz. Rota
+// nativa do Gin: POST /v1/submissions.
+func (h *ApiHandlers) SubmitAnswersHandler(c *gin.Context) error {
+	defer c.Request.Body.Close()
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return apierr.InvalidInput("Falha ao ler o corpo do pedido")
 	}
 	var req models.SubmissionRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return apierr.InvalidInput("JSON inválido: " + err.Error())
+	}
+
+	if req.QuizID == "" {
+		return apierr.InvalidInput("Input inválido: 'quiz_id' não pode estar em branco")
+	}
+	if len(req.Answers) == 0 {
+		return apierr.InvalidInput("Input inválido: 'answers' não pode estar vazio")
+	}
+
+	// UserID não vem do body (json:"-"): preenchemo-lo a partir do subject
+	// autenticado no token.
+	authUserID, ok := auth.UserIDFromContext(c.Request.Context())
+	if !ok {
+		return apierr.Unauthorized("Não autorizado")
+	}
+	req.UserID = strconv.Itoa(authUserID)
+
+	ctx := logging.AddAttrs(c.Request.Context(), "quiz_id", req.QuizID)
+
+	// Se o cliente enviou o header Idempotency-Key, guardamos também o hash
+	// do corpo do pedido, para o usecase poder detectar a mesma chave a ser
+	// reutilizada com um payload diferente.
+	if key := c.GetHeader("Idempotency-Key"); key != "" {
+		hash := sha256.Sum256(body)
+		req.IdempotencyKey = key
+		req.RequestHash = hex.EncodeToString(hash[:])
+	}
+
+	subResponse, err := h.quizUsecase.SubmitAnswers(ctx, req)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrNotFound):
+			return apierr.NotFound("Recurso não encontrado (ex: quiz_id ou user_id inválido)").WithCause(err)
+		case errors.Is(err, usecase.ErrInvalidInput):
+			return apierr.InvalidInput("Input inválido: " + err.Error())
+		default:
+			return apierr.Internal("Falha interna ao processar submissão").WithCause(err)
+		}
+	}
+
+	c.JSON(http.StatusOK, subResponse)
+	return nil
+}
+
+// SaveDraftHandler grava respostas parciais de um quiz ainda não submetido,
+// para um cliente poder retomar de onde parou em vez de perder o progresso
+func (h *ApiHandlers) SaveDraftHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		http.Error(w, "Método não permitido, use PATCH", http.StatusMethodNotAllowed)
+		return
+	}
+	var req models.DraftRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "JSON inválido: "+err.Error(), http.StatusBadRequest)
 		return
@@ -105,220 +284,386 @@ func (h *ApiHandlers) SubmitAnswersHandler(w http.ResponseWriter, r *http.Reques
 		http.Error(w, "Input inválido: 'quiz_id' não pode estar em branco", http.StatusBadRequest)
 		return
 	}
-	if req.UserID == "" {
-		http.Error(w, "Input inválido: 'user_id' não pode estar em branco", http.StatusBadRequest)
-		return
-	}
 	if len(req.Answers) == 0 {
 		http.Error(w, "Input inválido: 'answers' não pode estar vazio", http.StatusBadRequest)
 		return
 	}
 
-	subResponse, err := h.quizService.SubmitAnswers(r.Context(), req)
+	authUserID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Não autorizado", http.StatusUnauthorized)
+		return
+	}
+	req.UserID = strconv.Itoa(authUserID)
+
+	ctx := logging.AddAttrs(r.Context(), "quiz_id", req.QuizID)
 
+	err := h.quizUsecase.SaveDraft(ctx, req)
 	if err != nil {
-		if errors.Is(err, service.ErrNotFound) {
-			log.Printf("Erro 404 em SubmitAnswers: %v", err)
-			http.Error(w, "Recurso não encontrado (ex: quiz_id ou user_id inválido)", http.StatusNotFound)
-		} else if errors.Is(err, service.ErrInvalidInput) {
-			log.Printf("Erro 400 em SubmitAnswers: %v", err)
+		if errors.Is(err, usecase.ErrNotFound) {
+			logging.FromContext(ctx).Warn("recurso não encontrado em SaveDraft", "error", err)
+			http.Error(w, "Recurso não encontrado (ex: quiz_id inválido)", http.StatusNotFound)
+		} else if errors.Is(err, usecase.ErrInvalidInput) {
+			logging.FromContext(ctx).Warn("input inválido em SaveDraft", "error", err)
 			http.Error(w, "Input inválido: "+err.Error(), http.StatusBadRequest)
 		} else {
-			log.Printf("Erro 500 em SubmitAnswers: %v", err)
-			http.Error(w, "Falha interna ao processar submissão", http.StatusInternalServerError)
+			logging.FromContext(ctx).Error("falha interna em SaveDraft", "error", err)
+			http.Error(w, "Falha interna ao gravar draft", http.StatusInternalServerError)
 		}
 		return
 	}
 
+	response := SimpleMessageResponse{Message: "Draft gravado com sucesso"}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(subResponse); err != nil {
-		log.Printf("Erro ao enviar resposta JSON da submissão: %v", err)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logging.FromContext(ctx).Error("erro ao enviar resposta JSON de draft", "error", err)
 	}
 }
 
-// GetUserStatsHandler é o handler para as estatísticas do utilizador
-func (h *ApiHandlers) GetUserStatsHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
-		return
+// GetUserStatsHandler é o handler para as estatísticas do utilizador. Rota
+// nativa do Gin: GET /v1/users/:user_id/stats.
+func (h *ApiHandlers) GetUserStatsHandler(c *gin.Context) error {
+	userID := c.Param("user_id")
+
+	authUserID, ok := auth.UserIDFromContext(c.Request.Context())
+	if !ok {
+		return apierr.Unauthorized("Não autorizado")
 	}
-	userID := r.URL.Query().Get("user_id")
-	if userID == "" {
-		http.Error(w, "Input inválido: 'user_id' é obrigatório (query parameter)", http.StatusBadRequest)
-		return
+	if userID != strconv.Itoa(authUserID) {
+		return apierr.Forbidden("Acesso negado: user_id do token não corresponde ao do pedido")
 	}
 
-	statsResponse, err := h.quizService.GetUserStats(r.Context(), userID)
+	statsResponse, err := h.quizUsecase.GetUserStats(c.Request.Context(), userID)
 
 	if err != nil {
-		if errors.Is(err, service.ErrInvalidInput) {
-			log.Printf("Erro 400 em GetUserStats: %v", err)
-			http.Error(w, "Input inválido: "+err.Error(), http.StatusBadRequest)
-		} else {
-			log.Printf("Erro 500 em GetUserStats: %v", err)
-			http.Error(w, "Falha interna ao buscar estatísticas", http.StatusInternalServerError)
+		if errors.Is(err, usecase.ErrInvalidInput) {
+			return apierr.InvalidInput("Input inválido: " + err.Error())
 		}
-		return
+		return apierr.Internal("Falha interna ao buscar estatísticas").WithCause(err)
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(statsResponse); err != nil {
-		log.Printf("Erro ao enviar resposta JSON de estatísticas: %v", err)
+	c.JSON(http.StatusOK, statsResponse)
+	return nil
+}
+
+// GetUserSubmissionsHandler é o handler para o histórico de submissões. Rota
+// nativa do Gin: GET /v1/users/:user_id/submissions.
+func (h *ApiHandlers) GetUserSubmissionsHandler(c *gin.Context) error {
+	userID := c.Param("user_id")
+
+	authUserID, ok := auth.UserIDFromContext(c.Request.Context())
+	if !ok {
+		return apierr.Unauthorized("Não autorizado")
+	}
+	if userID != strconv.Itoa(authUserID) {
+		return apierr.Forbidden("Acesso negado: user_id do token não corresponde ao do pedido")
+	}
+
+	submissionsResponse, err := h.quizUsecase.ListUserSubmissions(c.Request.Context(), userID, parsePagination(c.Request))
+
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidInput) {
+			return apierr.InvalidInput("Input inválido: " + err.Error())
+		}
+		return apierr.Internal("Falha interna ao buscar histórico").WithCause(err)
 	}
+
+	c.JSON(http.StatusOK, submissionsResponse)
+	return nil
 }
 
-// GetUserSubmissionsHandler é o handler para o histórico de submissões
-func (h *ApiHandlers) GetUserSubmissionsHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
+// GetAllThemesHandler é o handler para listar todos os temas ativos. Rota
+// nativa do Gin: GET /v1/themes.
+func (h *ApiHandlers) GetAllThemesHandler(c *gin.Context) error {
+	temas, err := h.quizUsecase.GetAllActiveThemes(c.Request.Context())
+	if err != nil {
+		return apierr.Internal("Falha interna ao buscar temas").WithCause(err)
+	}
+	c.JSON(http.StatusOK, temas)
+	return nil
+}
+
+// DeactivateQuizHandler é o handler para o "soft-delete" de um quiz. Rota
+// nativa do Gin: PUT /v1/quizzes/:quiz_id/deactivate.
+func (h *ApiHandlers) DeactivateQuizHandler(c *gin.Context) error {
+	quizID := c.Param("quiz_id")
+
+	ctx := logging.AddAttrs(c.Request.Context(), "quiz_id", quizID)
+
+	err := h.quizUsecase.DeactivateQuiz(ctx, quizID)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrNotFound):
+			return apierr.NotFound("Recurso não encontrado (quiz_id não existe ou já está inativo)").WithCause(err)
+		case errors.Is(err, usecase.ErrInvalidInput):
+			return apierr.InvalidInput("Input inválido: " + err.Error())
+		default:
+			return apierr.Internal("Falha interna ao desativar quiz").WithCause(err)
+		}
+	}
+
+	c.JSON(http.StatusOK, SimpleMessageResponse{Message: "Quiz desativado com sucesso"})
+	return nil
+}
+
+// GetSubmissionDetailsHandler é o handler para os detalhes de uma submissão.
+// Só o dono da submissão ou um admin pode vê-los — ver
+// ListSubmissionCommentsHandler. Rota nativa do Gin:
+// GET /v1/submissions/:submission_id.
+func (h *ApiHandlers) GetSubmissionDetailsHandler(c *gin.Context) error {
+	submissionID := c.Param("submission_id")
+
+	ctx := logging.AddAttrs(c.Request.Context(), "submission_id", submissionID)
+
+	authUserID, ok := auth.UserIDFromContext(ctx)
+	if !ok {
+		return apierr.Unauthorized("Não autorizado")
+	}
+	tipo, _ := auth.TipoFromContext(ctx)
+
+	detailsResponse, err := h.quizUsecase.GetSubmissionDetails(ctx, submissionID, authUserID, tipo == "admin")
+
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrNotFound):
+			return apierr.NotFound("Recurso não encontrado (submission_id não existe)").WithCause(err)
+		case errors.Is(err, usecase.ErrInvalidInput):
+			return apierr.InvalidInput("Input inválido: " + err.Error())
+		default:
+			return apierr.Internal("Falha interna ao buscar detalhes da submissão").WithCause(err)
+		}
+	}
+
+	c.JSON(http.StatusOK, detailsResponse)
+	return nil
+}
+
+// PapersHandler agrupa o CRUD de quiz papers num único endpoint
+// (/api/v1/papers), despachando por método HTTP. Continua em net/http e é
+// montado no gin.Engine via gin.WrapH (ver NewRouter em router.go).
+func (h *ApiHandlers) PapersHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.createPaper(w, r)
+	case http.MethodGet:
+		h.getPaper(w, r)
+	case http.MethodPut:
+		h.updatePaper(w, r)
+	case http.MethodDelete:
+		h.deletePaper(w, r)
+	default:
 		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
-		return
 	}
-	userID := r.URL.Query().Get("user_id")
-	if userID == "" {
-		http.Error(w, "Input inválido: 'user_id' é obrigatório (query parameter)", http.StatusBadRequest)
+}
+
+// createPaper cria um novo quiz paper a partir de perguntas já existentes
+func (h *ApiHandlers) createPaper(w http.ResponseWriter, r *http.Request) {
+	var req models.CreatePaperRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "JSON inválido: "+err.Error(), http.StatusBadRequest)
 		return
 	}
+	defer r.Body.Close()
 
-	submissionsResponse, err := h.quizService.GetUserSubmissions(r.Context(), userID)
+	authUserID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Não autorizado", http.StatusUnauthorized)
+		return
+	}
+	req.CreatedBy = authUserID
 
+	createdPaper, err := h.quizUsecase.CreatePaper(r.Context(), req)
 	if err != nil {
-		if errors.Is(err, service.ErrInvalidInput) {
-			log.Printf("Erro 400 em GetUserSubmissions: %v", err)
+		if errors.Is(err, usecase.ErrInvalidInput) {
+			logging.FromContext(r.Context()).Warn("input inválido em CreatePaper", "error", err)
 			http.Error(w, "Input inválido: "+err.Error(), http.StatusBadRequest)
 		} else {
-			log.Printf("Erro 500 em GetUserSubmissions: %v", err)
-			http.Error(w, "Falha interna ao buscar histórico", http.StatusInternalServerError)
+			logging.FromContext(r.Context()).Error("falha interna em CreatePaper", "error", err)
+			http.Error(w, "Falha interna ao criar paper", http.StatusInternalServerError)
 		}
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(submissionsResponse); err != nil {
-		log.Printf("Erro ao enviar resposta JSON de histórico: %v", err)
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(createdPaper); err != nil {
+		logging.FromContext(r.Context()).Error("erro ao enviar resposta JSON de paper criado", "error", err)
 	}
 }
 
-// GetAllThemesHandler é o handler para listar todos os temas ativos
-func (h *ApiHandlers) GetAllThemesHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+// getPaper busca um paper pelo id e as suas perguntas ligadas
+func (h *ApiHandlers) getPaper(w http.ResponseWriter, r *http.Request) {
+	paperID := r.URL.Query().Get("paper_id")
+	if paperID == "" {
+		http.Error(w, "Input inválido: 'paper_id' é obrigatório (query parameter)", http.StatusBadRequest)
 		return
 	}
-	temas, err := h.quizService.GetAllActiveThemes(r.Context())
+
+	ctx := logging.AddAttrs(r.Context(), "paper_id", paperID)
+
+	foundPaper, questions, err := h.quizUsecase.GetPaper(ctx, paperID)
 	if err != nil {
-		log.Printf("Erro 500 em GetAllThemes: %v", err)
-		http.Error(w, "Falha interna ao buscar temas", http.StatusInternalServerError)
+		if errors.Is(err, usecase.ErrNotFound) {
+			logging.FromContext(ctx).Warn("recurso não encontrado em GetPaper", "error", err)
+			http.Error(w, "Recurso não encontrado (paper_id não existe)", http.StatusNotFound)
+		} else if errors.Is(err, usecase.ErrInvalidInput) {
+			logging.FromContext(ctx).Warn("input inválido em GetPaper", "error", err)
+			http.Error(w, "Input inválido: "+err.Error(), http.StatusBadRequest)
+		} else {
+			logging.FromContext(ctx).Error("falha interna em GetPaper", "error", err)
+			http.Error(w, "Falha interna ao buscar paper", http.StatusInternalServerError)
+		}
 		return
 	}
+
+	response := struct {
+		models.QuizPaper
+		Questions []models.QuizPaperQuestion `json:"questions"`
+	}{QuizPaper: *foundPaper, Questions: questions}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(temas); err != nil {
-		log.Printf("Erro ao enviar resposta JSON de temas: %v", err)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logging.FromContext(ctx).Error("erro ao enviar resposta JSON de paper", "error", err)
 	}
 }
 
-// DeactivateQuizHandler é o handler para o "soft-delete" de um quiz
-func (h *ApiHandlers) DeactivateQuizHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPut {
-		http.Error(w, "Método não permitido, use PUT", http.StatusMethodNotAllowed)
+// updatePaper substitui o título/perguntas de um paper (incrementa a versão)
+func (h *ApiHandlers) updatePaper(w http.ResponseWriter, r *http.Request) {
+	paperID := r.URL.Query().Get("paper_id")
+	if paperID == "" {
+		http.Error(w, "Input inválido: 'paper_id' é obrigatório (query parameter)", http.StatusBadRequest)
 		return
 	}
-	quizID := r.URL.Query().Get("quiz_id")
-	if quizID == "" {
-		http.Error(w, "Input inválido: 'quiz_id' é obrigatório (query parameter)", http.StatusBadRequest)
+
+	var req models.UpdatePaperRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "JSON inválido: "+err.Error(), http.StatusBadRequest)
 		return
 	}
+	defer r.Body.Close()
 
-	err := h.quizService.DeactivateQuiz(r.Context(), quizID)
+	ctx := logging.AddAttrs(r.Context(), "paper_id", paperID)
 
+	updatedPaper, err := h.quizUsecase.UpdatePaper(ctx, paperID, req)
 	if err != nil {
-		if errors.Is(err, service.ErrNotFound) {
-			log.Printf("Erro 404 em DeactivateQuiz: %v", err)
-			http.Error(w, "Recurso não encontrado (quiz_id não existe ou já está inativo)", http.StatusNotFound)
-		} else if errors.Is(err, service.ErrInvalidInput) {
-			log.Printf("Erro 400 em DeactivateQuiz: %v", err)
+		if errors.Is(err, usecase.ErrNotFound) {
+			logging.FromContext(ctx).Warn("recurso não encontrado em UpdatePaper", "error", err)
+			http.Error(w, "Recurso não encontrado (paper_id não existe)", http.StatusNotFound)
+		} else if errors.Is(err, usecase.ErrInvalidInput) {
+			logging.FromContext(ctx).Warn("input inválido em UpdatePaper", "error", err)
 			http.Error(w, "Input inválido: "+err.Error(), http.StatusBadRequest)
 		} else {
-			log.Printf("Erro 500 em DeactivateQuiz: %v", err)
-			http.Error(w, "Falha interna ao desativar quiz", http.StatusInternalServerError)
+			logging.FromContext(ctx).Error("falha interna em UpdatePaper", "error", err)
+			http.Error(w, "Falha interna ao atualizar paper", http.StatusInternalServerError)
 		}
 		return
 	}
 
-	response := SimpleMessageResponse{Message: "Quiz desativado com sucesso"}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Printf("Erro ao enviar resposta JSON de desativação: %v", err)
+	if err := json.NewEncoder(w).Encode(updatedPaper); err != nil {
+		logging.FromContext(ctx).Error("erro ao enviar resposta JSON de paper atualizado", "error", err)
 	}
 }
 
-// GetSubmissionDetailsHandler é o handler para os detalhes de uma submissão
-func (h *ApiHandlers) GetSubmissionDetailsHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
-		return
-	}
-	submissionID := r.URL.Query().Get("submission_id")
-	if submissionID == "" {
-		http.Error(w, "Input inválido: 'submission_id' é obrigatório (query parameter)", http.StatusBadRequest)
+// deletePaper faz o "soft-delete" de um paper
+func (h *ApiHandlers) deletePaper(w http.ResponseWriter, r *http.Request) {
+	paperID := r.URL.Query().Get("paper_id")
+	if paperID == "" {
+		http.Error(w, "Input inválido: 'paper_id' é obrigatório (query parameter)", http.StatusBadRequest)
 		return
 	}
 
-	detailsResponse, err := h.quizService.GetSubmissionDetails(r.Context(), submissionID)
+	ctx := logging.AddAttrs(r.Context(), "paper_id", paperID)
 
+	err := h.quizUsecase.DeletePaper(ctx, paperID)
 	if err != nil {
-		if errors.Is(err, service.ErrNotFound) {
-			log.Printf("Erro 404 em GetSubmissionDetails: %v", err)
-			http.Error(w, "Recurso não encontrado (submission_id não existe)", http.StatusNotFound)
-		} else if errors.Is(err, service.ErrInvalidInput) {
-			log.Printf("Erro 400 em GetSubmissionDetails: %v", err)
+		if errors.Is(err, usecase.ErrNotFound) {
+			logging.FromContext(ctx).Warn("recurso não encontrado em DeletePaper", "error", err)
+			http.Error(w, "Recurso não encontrado (paper_id não existe ou já está inativo)", http.StatusNotFound)
+		} else if errors.Is(err, usecase.ErrInvalidInput) {
+			logging.FromContext(ctx).Warn("input inválido em DeletePaper", "error", err)
 			http.Error(w, "Input inválido: "+err.Error(), http.StatusBadRequest)
 		} else {
-			log.Printf("Erro 500 em GetSubmissionDetails: %v", err)
-			http.Error(w, "Falha interna ao buscar detalhes da submissão", http.StatusInternalServerError)
+			logging.FromContext(ctx).Error("falha interna em DeletePaper", "error", err)
+			http.Error(w, "Falha interna ao desativar paper", http.StatusInternalServerError)
 		}
 		return
 	}
 
+	response := SimpleMessageResponse{Message: "Paper desativado com sucesso"}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(detailsResponse); err != nil {
-		log.Printf("Erro ao enviar resposta JSON de detalhes da submissão: %v", err)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logging.FromContext(ctx).Error("erro ao enviar resposta JSON de desativação de paper", "error", err)
 	}
 }
 
-// GetQuizzesByThemeHandler é o handler para listar quizzes de um tema
-func (h *ApiHandlers) GetQuizzesByThemeHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
+// InstantiatePaperHandler materializa um Quiz runnable a partir de um paper,
+// para o utilizador autenticado poder resolvê-lo como qualquer outro quiz.
+func (h *ApiHandlers) InstantiatePaperHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
 		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
 		return
 	}
-	themeID := r.URL.Query().Get("theme_id")
-	if themeID == "" {
-		http.Error(w, "Input inválido: 'theme_id' é obrigatório (query parameter)", http.StatusBadRequest)
+	paperID := r.URL.Query().Get("paper_id")
+	if paperID == "" {
+		http.Error(w, "Input inválido: 'paper_id' é obrigatório (query parameter)", http.StatusBadRequest)
 		return
 	}
 
-	quizzesResponse, err := h.quizService.GetActiveQuizzesByTheme(r.Context(), themeID)
+	authUserID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Não autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	ctx := logging.AddAttrs(r.Context(), "paper_id", paperID)
 
+	instantiatedQuiz, perguntas, err := h.quizUsecase.InstantiatePaper(ctx, paperID, models.InstantiatePaperRequest{UserID: strconv.Itoa(authUserID)})
 	if err != nil {
-		if errors.Is(err, service.ErrInvalidInput) {
-			log.Printf("Erro 400 em GetQuizzesByTheme: %v", err)
+		if errors.Is(err, usecase.ErrNotFound) {
+			logging.FromContext(ctx).Warn("recurso não encontrado em InstantiatePaper", "error", err)
+			http.Error(w, "Recurso não encontrado (paper_id não existe)", http.StatusNotFound)
+		} else if errors.Is(err, usecase.ErrInvalidInput) {
+			logging.FromContext(ctx).Warn("input inválido em InstantiatePaper", "error", err)
 			http.Error(w, "Input inválido: "+err.Error(), http.StatusBadRequest)
 		} else {
-			log.Printf("Erro 500 em GetQuizzesByTheme: %v", err)
-			http.Error(w, "Falha interna ao buscar quizzes", http.StatusInternalServerError)
+			logging.FromContext(ctx).Error("falha interna em InstantiatePaper", "error", err)
+			http.Error(w, "Falha interna ao instanciar paper", http.StatusInternalServerError)
 		}
 		return
 	}
 
+	response := struct {
+		Quiz      models.Quiz       `json:"quiz"`
+		Perguntas []models.Pergunta `json:"perguntas"`
+	}{Quiz: *instantiatedQuiz, Perguntas: perguntas}
+
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(quizzesResponse); err != nil {
-		log.Printf("Erro ao enviar resposta JSON de quizzes: %v", err)
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logging.FromContext(ctx).Error("erro ao enviar resposta JSON de instanciação de paper", "error", err)
 	}
 }
+
+// GetQuizzesByThemeHandler é o handler para listar quizzes de um tema. Rota
+// nativa do Gin: GET /v1/themes/:theme_id/quizzes.
+func (h *ApiHandlers) GetQuizzesByThemeHandler(c *gin.Context) error {
+	themeID := c.Param("theme_id")
+
+	quizzesResponse, err := h.quizUsecase.ListQuizzesByTheme(c.Request.Context(), themeID, parsePagination(c.Request))
+
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidInput) {
+			return apierr.InvalidInput("Input inválido: " + err.Error())
+		}
+		return apierr.Internal("Falha interna ao buscar quizzes").WithCause(err)
+	}
+
+	c.JSON(http.StatusOK, quizzesResponse)
+	return nil
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"quizz-core/internal/apierr"
+	"quizz-core/internal/auth"
+	"quizz-core/internal/logging"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+// corsMiddleware aplica um CORS permissivo (qualquer origem) a todas as
+// respostas. Não há ainda uma lista de origens configurável neste projeto;
+// se isso vier a ser necessário, deve ler-se de config.Config tal como o
+// resto das opções de ambiente.
+func corsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Access-Control-Allow-Origin", "*")
+		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, Idempotency-Key")
+		if c.Request.Method == http.MethodOptions {
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		c.Next()
+	}
+}
+
+// ginPanicJSON é o handler de gin.CustomRecovery chamado quando um handler
+// entra em pânico: devolve o mesmo documento problem+json dos restantes
+// erros (ver apierr.WriteError), em vez da página de texto simples que o
+// gin.Recovery() default devolveria.
+func ginPanicJSON(c *gin.Context, recovered any) {
+	logging.FromContext(c.Request.Context()).Error("pânico no handler", "recovered", recovered)
+	apierr.WriteError(c, apierr.Internal("Erro interno do servidor"))
+}
+
+// NewRouter constrói o gin.Engine que serve toda a API: regista o pipeline de
+// middlewares transversais (request-id/structured logging, recuperação de
+// pânico, CORS, sessão) e as rotas nativas do Gin com parâmetros tipados.
+// Rotas que ainda não foram portadas para o Gin (auth, papers, health,
+// metrics, streaming) continuam a ser wireadas pelo cmd/server/main.go
+// diretamente no *gin.Engine devolvido, via gin.WrapH.
+//
+// CreateQuizHandler, SubmitAnswersHandler, GetUserStatsHandler e
+// GetUserSubmissionsHandler usam requireSession em vez de requireAuth: já
+// não confiam num user_id vindo do cliente (path/body), mas sim no que a
+// sessão guardou em auth.LoginSessionHandler — ver auth.RequireSessionGin.
+// GetSubmissionDetailsHandler e DeactivateQuizHandler continuam a exigir JWT,
+// por agora reservado a clientes/automação que preferem bearer tokens a
+// cookies. A proteção CSRF destes POSTs vem do SameSite do cookie de sessão
+// (ver auth.CookieOptions) e não de um token CSRF dedicado — ver o
+// comentário em auth.CookieOptions sobre o que isso implica ao usar
+// SameSite=None.
+//
+// Os comentários de quizzes seguem a mesma leitura-pública/escrita-protegida
+// dos próprios quizzes (ver GetAllThemesHandler); os de submissões (incluindo
+// GetSubmissionDetailsHandler) exigem requireAuth em toda a rota, mas isso só
+// garante uma identidade válida — quem é o dono da submissão é verificado à
+// parte no usecase, contra o utilizador autenticado (ver
+// checkSubmissionOwnership em usecase.go), porque submissões são dados
+// privados do utilizador que as fez. O export.json fica reservado a admins,
+// tal como DeactivateQuizHandler.
+func NewRouter(h *ApiHandlers, jwtSecret string, sessionStore sessions.Store, cookieOpts auth.CookieOptions, logger *slog.Logger, accessLogFormat string) *gin.Engine {
+	engine := gin.New()
+	engine.Use(logging.GinMiddleware(logger, accessLogFormat))
+	engine.Use(gin.CustomRecovery(ginPanicJSON))
+	engine.Use(corsMiddleware())
+	engine.Use(sessions.Sessions(auth.SessionCookieName, sessionStore))
+	engine.Use(auth.SessionOptionsMiddleware(cookieOpts))
+
+	requireAuth := auth.RequireAuthGin(jwtSecret)
+	requireAdmin := auth.RequireAdminGin(jwtSecret)
+	requireSession := auth.RequireSessionGin()
+
+	v1 := engine.Group("/v1")
+
+	quizzes := v1.Group("/quizzes")
+	quizzes.POST("", requireSession, apierr.Wrap(h.CreateQuizHandler))
+	quizzes.PUT("/:quiz_id/deactivate", requireAdmin, apierr.Wrap(h.DeactivateQuizHandler))
+	quizzes.GET("/:quiz_id/comments", apierr.Wrap(h.ListQuizCommentsHandler))
+	quizzes.POST("/:quiz_id/comments", requireSession, apierr.Wrap(h.CreateQuizCommentHandler))
+	quizzes.DELETE("/:quiz_id/comments/:comment_id", requireSession, apierr.Wrap(h.DeleteQuizCommentHandler))
+	quizzes.GET("/:quiz_id/comments/export.json", requireAdmin, apierr.Wrap(h.ExportQuizCommentsHandler))
+
+	submissions := v1.Group("/submissions")
+	submissions.POST("", requireSession, apierr.Wrap(h.SubmitAnswersHandler))
+	submissions.GET("/:submission_id", requireAuth, apierr.Wrap(h.GetSubmissionDetailsHandler))
+	submissions.GET("/:submission_id/comments", requireAuth, apierr.Wrap(h.ListSubmissionCommentsHandler))
+	submissions.POST("/:submission_id/comments", requireAuth, apierr.Wrap(h.CreateSubmissionCommentHandler))
+	submissions.DELETE("/:submission_id/comments/:comment_id", requireAuth, apierr.Wrap(h.DeleteSubmissionCommentHandler))
+
+	users := v1.Group("/users/:user_id")
+	users.GET("/stats", requireSession, apierr.Wrap(h.GetUserStatsHandler))
+	users.GET("/submissions", requireSession, apierr.Wrap(h.GetUserSubmissionsHandler))
+
+	themes := v1.Group("/themes")
+	themes.GET("", apierr.Wrap(h.GetAllThemesHandler))
+	themes.GET("/:theme_id/quizzes", apierr.Wrap(h.GetQuizzesByThemeHandler))
+
+	return engine
+}
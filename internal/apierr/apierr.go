@@ -0,0 +1,169 @@
+// Package apierr centraliza o mapeamento de erros para respostas HTTP dos
+// handlers do pacote api: em vez de cada handler repetir a sua própria
+// ladder de errors.Is(...)/writeErr(...), um handler devolve um *APIError
+// (ou um erro genérico, tratado como 500) e Wrap trata de serializá-lo como
+// um documento RFC 7807 (application/problem+json) com um 'code' estável e
+// o request_id do pedido. Os handlers ainda em net/http (ver router.go) não
+// passam por aqui — continuam em http.Error até serem portados para o Gin.
+// Os handlers de internal/auth também ficam de fora por agora: já são
+// nativos do Gin mas pertencem a outro pacote, com o seu próprio envelope de
+// erro ({"errmsg": ...}); migrá-los é trabalho de outra mudança.
+package apierr
+
+import (
+	"errors"
+	"net/http"
+
+	"quizz-core/internal/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIError é o erro tipado que os handlers devolvem: Code é estável e
+// pensado para clientes automatizarem sobre ele (nunca muda de mensagem
+// para mensagem); Message é o texto humano, seguro para expor ao cliente.
+// Cause é opcional e nunca é exposto na resposta — serve só para WriteError
+// ter o erro interno completo (ex: o erro devolvido pelo usecase) disponível
+// para o log, quando Message é propositadamente mais genérico.
+type APIError struct {
+	Code       string
+	HTTPStatus int
+	Message    string
+	Cause      error
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Cause
+}
+
+// WithCause anexa o erro interno que originou este APIError, para fins de
+// log (ver WriteError). Devolve o mesmo *APIError, para poder ser encadeado
+// no próprio return do handler: `return apierr.Internal("...").WithCause(err)`.
+func (e *APIError) WithCause(cause error) *APIError {
+	e.Cause = cause
+	return e
+}
+
+func newError(code string, status int, message string) *APIError {
+	return &APIError{Code: code, HTTPStatus: status, Message: message}
+}
+
+// NotFound devolve um *APIError 404 (recurso inexistente).
+func NotFound(message string) *APIError {
+	return newError("not_found", http.StatusNotFound, message)
+}
+
+// InvalidInput devolve um *APIError 400 (input do cliente inválido).
+func InvalidInput(message string) *APIError {
+	return newError("invalid_input", http.StatusBadRequest, message)
+}
+
+// Conflict devolve um *APIError 409 (ex: um recurso único já existe).
+func Conflict(message string) *APIError {
+	return newError("conflict", http.StatusConflict, message)
+}
+
+// Unauthorized devolve um *APIError 401 (sem identidade válida).
+func Unauthorized(message string) *APIError {
+	return newError("unauthorized", http.StatusUnauthorized, message)
+}
+
+// Forbidden devolve um *APIError 403 (identidade válida, sem permissão).
+func Forbidden(message string) *APIError {
+	return newError("forbidden", http.StatusForbidden, message)
+}
+
+// RateLimited devolve um *APIError 429.
+func RateLimited(message string) *APIError {
+	return newError("rate_limited", http.StatusTooManyRequests, message)
+}
+
+// Upstream devolve um *APIError 502 (uma dependência externa devolveu uma
+// resposta inválida ou inesperada).
+func Upstream(message string) *APIError {
+	return newError("upstream_unavailable", http.StatusBadGateway, message)
+}
+
+// Unavailable devolve um *APIError 503 (o serviço está temporariamente
+// incapaz de atender o pedido, ex: o circuit breaker do provider de LLM
+// está aberto, ou a base de dados está em baixo — ver usecase.ErrUnavailable
+// e CheckHealth).
+func Unavailable(message string) *APIError {
+	return newError("unavailable", http.StatusServiceUnavailable, message)
+}
+
+// Internal devolve um *APIError 500. É também o que Wrap usa quando o
+// handler devolve um erro genérico em vez de um *APIError.
+func Internal(message string) *APIError {
+	return newError("internal", http.StatusInternalServerError, message)
+}
+
+// problemDocument é o corpo RFC 7807 (application/problem+json) emitido por
+// Wrap. Type não aponta para documentação pública (este projeto não tem
+// nenhuma): é uma URN estável derivada do Code, só para o campo nunca ficar
+// vazio.
+type problemDocument struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail"`
+	Code      string `json:"code"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// requestIDHeader tem de coincidir com o header que logging.GinMiddleware já
+// define na resposta, para WriteError conseguir reaproveitar o mesmo
+// request_id sem precisar de outro canal de propagação.
+const requestIDHeader = "X-Request-ID"
+
+// WriteError serializa err como um documento problem+json na resposta. Um
+// erro que não seja *APIError é tratado como Internal, para nunca vazar a
+// mensagem interna de um erro não mapeado ao cliente. Também regista o erro
+// original (Warn para 4xx, Error para 5xx), centralizando o que antes cada
+// handler fazia à mão antes de chamar writeErr.
+func WriteError(c *gin.Context, err error) {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		apiErr = Internal("Erro interno do servidor")
+	}
+
+	logArgs := []any{"error", apiErr, "code", apiErr.Code}
+	if apiErr.Cause != nil {
+		logArgs = append(logArgs, "cause", apiErr.Cause)
+	}
+	logger := logging.FromContext(c.Request.Context())
+	if apiErr.HTTPStatus >= http.StatusInternalServerError {
+		logger.Error("falha ao processar pedido", logArgs...)
+	} else {
+		logger.Warn("falha ao processar pedido", logArgs...)
+	}
+
+	c.Header("Content-Type", "application/problem+json")
+	c.AbortWithStatusJSON(apiErr.HTTPStatus, problemDocument{
+		Type:      "urn:quizz-core:error:" + apiErr.Code,
+		Title:     http.StatusText(apiErr.HTTPStatus),
+		Status:    apiErr.HTTPStatus,
+		Detail:    apiErr.Message,
+		Code:      apiErr.Code,
+		RequestID: c.Writer.Header().Get(requestIDHeader),
+	})
+}
+
+// HandlerFunc é a assinatura que os handlers portados para apierr usam em
+// vez de gin.HandlerFunc: devolver o erro em vez de escrevê-lo diretamente
+// deixa o mapeamento para HTTP inteiramente a cargo de Wrap/WriteError.
+type HandlerFunc func(c *gin.Context) error
+
+// Wrap adapta um apierr.HandlerFunc para gin.HandlerFunc, para poder ser
+// registado nas rotas do *gin.Engine como qualquer outro handler.
+func Wrap(fn HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := fn(c); err != nil {
+			WriteError(c, err)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// Package auth implementa o subsistema de autenticação: emissão/validação
+// de JWTs e hashing de passwords.
+package auth
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Claims são as claims que colocamos dentro do JWT: 'sub' identifica o
+// utilizador (mesmo ID usado em utilizadores/quizzes/submissões) e 'tipo'
+// transporta o papel (ex: "admin") para a gate de RequireAdminGin.
+type Claims struct {
+	Tipo string `json:"tipo,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// HashPassword gera o hash bcrypt de uma password em texto simples
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("falha ao gerar hash da password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// CheckPassword compara uma password em texto simples com o hash guardado
+func CheckPassword(hash, password string) error {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+}
+
+// GenerateToken emite um JWT HS256 assinado com 'secret', com sub=userID e
+// exp=agora+ttl
+func GenerateToken(secret string, userID int, tipo string, ttl time.Duration) (string, error) {
+	claims := Claims{
+		Tipo: tipo,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   fmt.Sprintf("%d", userID),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString([]byte(secret))
+	if err != nil {
+		return "", fmt.Errorf("falha ao assinar JWT: %w", err)
+	}
+	return signed, nil
+}
+
+// ParseToken valida a assinatura e a expiração de um JWT e devolve as claims
+func ParseToken(secret, tokenStr string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenStr, claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("método de assinatura inesperado: %v", t.Header["alg"])
+		}
+		return []byte(secret), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("falha ao validar JWT: %w", err)
+	}
+	if !token.Valid {
+		return nil, fmt.Errorf("JWT inválido")
+	}
+	return claims, nil
+}
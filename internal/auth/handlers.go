@@ -0,0 +1,191 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"quizz-core/internal/logging"
+	"quizz-core/internal/models"
+	"quizz-core/internal/repository/user"
+	"strconv"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+)
+
+// Handlers agrupa as dependências dos endpoints de autenticação
+type Handlers struct {
+	userRepo     user.Repository
+	jwtSecret    string
+	jwtTokenTTL  time.Duration
+	sessionStore sessions.Store
+	cookieOpts   CookieOptions
+}
+
+// NewHandlers é o construtor dos handlers de autenticação
+func NewHandlers(userRepo user.Repository, jwtSecret string, jwtTokenTTL time.Duration, sessionStore sessions.Store, cookieOpts CookieOptions) *Handlers {
+	return &Handlers{
+		userRepo:     userRepo,
+		jwtSecret:    jwtSecret,
+		jwtTokenTTL:  jwtTokenTTL,
+		sessionStore: sessionStore,
+		cookieOpts:   cookieOpts,
+	}
+}
+
+// RegisterHandler cria um novo utilizador e já devolve um JWT, como o login
+func (h *Handlers) RegisterHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.RegisterRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "JSON inválido: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if req.Nome == "" || req.Password == "" {
+		http.Error(w, "Input inválido: 'nome' e 'password' são obrigatórios", http.StatusBadRequest)
+		return
+	}
+
+	passwordHash, err := HashPassword(req.Password)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("erro ao gerar hash da password", "error", err)
+		http.Error(w, "Falha interna ao registar utilizador", http.StatusInternalServerError)
+		return
+	}
+
+	user, err := h.userRepo.CreateUtilizador(r.Context(), req.Nome, passwordHash, "")
+	if err != nil {
+		logging.FromContext(r.Context()).Error("erro ao criar utilizador", "error", err)
+		http.Error(w, "Falha ao registar utilizador (nome já existe?)", http.StatusConflict)
+		return
+	}
+
+	token, err := GenerateToken(h.jwtSecret, user.ID, user.Tipo, h.jwtTokenTTL)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("erro ao gerar JWT pós-registo", "error", err)
+		http.Error(w, "Falha interna ao gerar token", http.StatusInternalServerError)
+		return
+	}
+
+	writeLoginResponse(r.Context(), w, token, user.ID)
+}
+
+// LoginHandler valida a password e devolve um JWT
+func (h *Handlers) LoginHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "JSON inválido: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if req.Nome == "" || req.Password == "" {
+		http.Error(w, "Input inválido: 'nome' e 'password' são obrigatórios", http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.userRepo.GetUtilizadorByNome(r.Context(), req.Nome)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Credenciais inválidas", http.StatusUnauthorized)
+			return
+		}
+		logging.FromContext(r.Context()).Error("erro ao buscar utilizador por nome", "error", err)
+		http.Error(w, "Falha interna ao autenticar", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CheckPassword(user.Password, req.Password); err != nil {
+		http.Error(w, "Credenciais inválidas", http.StatusUnauthorized)
+		return
+	}
+
+	token, err := GenerateToken(h.jwtSecret, user.ID, user.Tipo, h.jwtTokenTTL)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("erro ao gerar JWT de login", "error", err)
+		http.Error(w, "Falha interna ao gerar token", http.StatusInternalServerError)
+		return
+	}
+
+	writeLoginResponse(r.Context(), w, token, user.ID)
+}
+
+// AdminCreateUserHandler cria uma conta para outro utilizador com uma
+// password inicial gerada no servidor (o chamador nunca a escolhe). Só
+// acessível a admins (ver RequireAdminGin em main.go).
+func (h *Handlers) AdminCreateUserHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.AdminCreateUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "JSON inválido: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if req.Nome == "" || req.Tipo == "" {
+		http.Error(w, "Input inválido: 'nome' e 'tipo' são obrigatórios", http.StatusBadRequest)
+		return
+	}
+
+	initialPassword, err := GenerateInitialPassword()
+	if err != nil {
+		logging.FromContext(r.Context()).Error("erro ao gerar password inicial", "error", err)
+		http.Error(w, "Falha interna ao criar utilizador", http.StatusInternalServerError)
+		return
+	}
+
+	passwordHash, err := HashPassword(initialPassword)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("erro ao gerar hash da password inicial", "error", err)
+		http.Error(w, "Falha interna ao criar utilizador", http.StatusInternalServerError)
+		return
+	}
+
+	user, err := h.userRepo.CreateUtilizador(r.Context(), req.Nome, passwordHash, req.Tipo)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("erro ao criar utilizador (admin)", "error", err)
+		http.Error(w, "Falha ao criar utilizador (nome já existe?)", http.StatusConflict)
+		return
+	}
+
+	response := models.AdminCreateUserResponse{
+		UserID:          strconv.Itoa(user.ID),
+		Nome:            user.Nome,
+		Tipo:            user.Tipo,
+		InitialPassword: initialPassword,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logging.FromContext(r.Context()).Error("erro ao enviar resposta JSON de criação de utilizador", "error", err)
+	}
+}
+
+func writeLoginResponse(ctx context.Context, w http.ResponseWriter, token string, userID int) {
+	response := models.LoginResponse{
+		Token:  token,
+		UserID: strconv.Itoa(userID),
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logging.FromContext(ctx).Error("erro ao enviar resposta JSON de autenticação", "error", err)
+	}
+}
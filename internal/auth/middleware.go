@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"quizz-core/internal/logging"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type contextKey string
+
+const (
+	userIDContextKey contextKey = "auth_user_id"
+	tipoContextKey   contextKey = "auth_tipo"
+)
+
+// authenticateGin extrai e valida o JWT de 'Authorization: Bearer <token>' e
+// guarda o user_id/tipo resolvidos no contexto de c.Request, para
+// UserIDFromContext/TipoFromContext continuarem a funcionar sem alterações
+// nos handlers. Devolve false (e já terá chamado c.AbortWithStatusJSON) se o
+// token for ausente/inválido — quem chama NÃO deve chamar c.Next() nesse
+// caso. Não chama c.Next() a mais: é a própria gin.HandlerFunc (RequireAuthGin
+// ou RequireAdminGin) quem decide quando a cadeia deve continuar.
+func authenticateGin(c *gin.Context, secret string) bool {
+	header := c.GetHeader("Authorization")
+	parts := strings.SplitN(header, " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"errmsg": "Não autorizado: token ausente"})
+		return false
+	}
+
+	claims, err := ParseToken(secret, parts[1])
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"errmsg": "Não autorizado: " + err.Error()})
+		return false
+	}
+
+	userID, err := strconv.Atoi(claims.Subject)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"errmsg": "Não autorizado: token com subject inválido"})
+		return false
+	}
+
+	ctx := context.WithValue(c.Request.Context(), userIDContextKey, userID)
+	ctx = context.WithValue(ctx, tipoContextKey, claims.Tipo)
+	ctx = logging.AddAttrs(ctx, "user_id", userID)
+	c.Request = c.Request.WithContext(ctx)
+	return true
+}
+
+// RequireAuthGin extrai e valida o JWT de 'Authorization: Bearer <token>',
+// e injeta o user_id e o tipo resolvidos no contexto do pedido. Pedidos
+// sem um token válido recebem 401.
+func RequireAuthGin(secret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !authenticateGin(c, secret) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireAdminGin encadeia a mesma validação de RequireAuthGin e exige
+// tipo == "admin", devolvendo 403 caso contrário.
+func RequireAdminGin(secret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !authenticateGin(c, secret) {
+			return
+		}
+		tipo, _ := TipoFromContext(c.Request.Context())
+		if tipo != "admin" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"errmsg": "Acesso negado: requer privilégios de admin"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// UserIDFromContext devolve o user_id resolvido pelo RequireAuthGin
+func UserIDFromContext(ctx context.Context) (int, bool) {
+	userID, ok := ctx.Value(userIDContextKey).(int)
+	return userID, ok
+}
+
+// TipoFromContext devolve o tipo/papel resolvido pelo RequireAuthGin
+func TipoFromContext(ctx context.Context) (string, bool) {
+	tipo, ok := ctx.Value(tipoContextKey).(string)
+	return tipo, ok
+}
@@ -0,0 +1,27 @@
+package auth
+
+import (
+	"fmt"
+
+	"github.com/sethvargo/go-password/password"
+)
+
+// initialPasswordLength/initialPasswordDigits/initialPasswordSymbols seguem
+// o perfil recomendado pela sethvargo/go-password para passwords iniciais
+// fortes mas ainda digitáveis por um utilizador à primeira vez.
+const (
+	initialPasswordLength  = 16
+	initialPasswordDigits  = 4
+	initialPasswordSymbols = 2
+)
+
+// GenerateInitialPassword gera uma password inicial aleatória para contas
+// criadas por um admin (ver AdminCreateUserHandler). O utilizador deve
+// trocá-la no primeiro login.
+func GenerateInitialPassword() (string, error) {
+	pw, err := password.Generate(initialPasswordLength, initialPasswordDigits, initialPasswordSymbols, false, false)
+	if err != nil {
+		return "", fmt.Errorf("falha ao gerar password inicial: %w", err)
+	}
+	return pw, nil
+}
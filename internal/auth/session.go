@@ -0,0 +1,171 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"quizz-core/internal/logging"
+	"quizz-core/internal/models"
+	"strconv"
+	"strings"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/memstore"
+	"github.com/gin-gonic/gin"
+	gorillasessions "github.com/gorilla/sessions"
+)
+
+// SessionCookieName é o nome do cookie de sessão emitido por
+// LoginSessionHandler.
+const SessionCookieName = "qc_session"
+
+const (
+	sessionUserIDKey = "user_id"
+	sessionTipoKey   = "tipo"
+)
+
+// CookieOptions controla as flags do cookie de sessão (ver as opções
+// SESSION_COOKIE_* em config.Config). SameSite=Lax/Strict (o default) é o
+// que protege os endpoints que passaram a usar requireSession (ver
+// api.NewRouter) contra CSRF: o browser não anexa o cookie em pedidos
+// cross-site. SameSite=None desliga essa proteção — só faz sentido quando o
+// frontend corre numa origem diferente da API, e nesse caso exige uma
+// defesa CSRF própria (ex: token custom header) que este projeto ainda não
+// tem.
+type CookieOptions struct {
+	Secure   bool
+	HTTPOnly bool
+	SameSite http.SameSite
+}
+
+// ParseSameSite converte o valor de SESSION_COOKIE_SAMESITE ("lax", "strict"
+// ou "none") no enum do net/http; qualquer valor desconhecido cai no default
+// (Lax). SameSite=None só é aceite com Secure=true (exigido pelos próprios
+// browsers); LoadConfig força Secure quando isto não é respeitado — ver
+// config.Config.
+func ParseSameSite(value string) http.SameSite {
+	switch strings.ToLower(value) {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
+
+// NewSessionStore cria o backing store das sessões. Em desenvolvimento isto
+// é um memstore (perde-se ao reiniciar o processo); em produção, trocar para
+// um store distribuído (Redis, Postgres) é só substituir esta chamada por
+// outra implementação de sessions.Store — o resto do subsistema não muda.
+func NewSessionStore(secret string) sessions.Store {
+	return memstore.NewStore([]byte(secret))
+}
+
+// SessionOptionsMiddleware aplica as flags de cookie configuradas a toda a
+// sessão resolvida pelo sessions.Sessions já registado antes dele na cadeia.
+func SessionOptionsMiddleware(opts CookieOptions) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sessions.Default(c).Options(sessions.Options{
+			Path:     "/",
+			HttpOnly: opts.HTTPOnly,
+			Secure:   opts.Secure,
+			SameSite: opts.SameSite,
+		})
+		c.Next()
+	}
+}
+
+// LoginSessionHandler é o equivalente a LoginHandler para autenticação por
+// sessão/cookie em vez de JWT: valida nome+password e, em vez de devolver um
+// token no corpo, grava user_id/tipo na sessão. Em vez de reaproveitar a
+// sessão já associada ao pedido (sessions.Default(c)), constrói uma sessão
+// nova do zero via gorillasessions.NewSession: se o cliente trouxer um ID de
+// sessão válido fixado antes do login (ex: por um atacante, visitando o site
+// antes da vítima), session.Clear() sozinho não o invalidaria — só esvazia
+// os valores, mantendo o mesmo ID. Uma sessão nova começa sempre com ID
+// vazio, e o Store só atribui um ID novo quando grava uma sessão nessas
+// condições (mitigação de session fixation).
+func (h *Handlers) LoginSessionHandler(c *gin.Context) {
+	var req models.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errmsg": "JSON inválido: " + err.Error()})
+		return
+	}
+	if req.Nome == "" || req.Password == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"errmsg": "Input inválido: 'nome' e 'password' são obrigatórios"})
+		return
+	}
+
+	usr, err := h.userRepo.GetUtilizadorByNome(c.Request.Context(), req.Nome)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusUnauthorized, gin.H{"errmsg": "Credenciais inválidas"})
+			return
+		}
+		logging.FromContext(c.Request.Context()).Error("erro ao buscar utilizador por nome", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"errmsg": "Falha interna ao autenticar"})
+		return
+	}
+
+	if err := CheckPassword(usr.Password, req.Password); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"errmsg": "Credenciais inválidas"})
+		return
+	}
+
+	fresh := gorillasessions.NewSession(h.sessionStore, SessionCookieName)
+	fresh.Options = &gorillasessions.Options{
+		Path:     "/",
+		HttpOnly: h.cookieOpts.HTTPOnly,
+		Secure:   h.cookieOpts.Secure,
+		SameSite: h.cookieOpts.SameSite,
+	}
+	fresh.Values[sessionUserIDKey] = usr.ID
+	fresh.Values[sessionTipoKey] = usr.Tipo
+	if err := fresh.Save(c.Request, c.Writer); err != nil {
+		logging.FromContext(c.Request.Context()).Error("erro ao gravar sessão de login", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"errmsg": "Falha interna ao autenticar"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.LoginResponse{UserID: strconv.Itoa(usr.ID)})
+}
+
+// LogoutHandler termina a sessão atual: MaxAge negativo instrui o store a
+// apagar a entrada correspondente (não só limpar os valores, como
+// session.Clear() faria) e expira o cookie no cliente.
+func (h *Handlers) LogoutHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	session.Options(sessions.Options{MaxAge: -1})
+	if err := session.Save(); err != nil {
+		logging.FromContext(c.Request.Context()).Error("erro ao limpar sessão de logout", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"errmsg": "Falha interna ao terminar sessão"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Sessão terminada com sucesso"})
+}
+
+// RequireSessionGin lê user_id/tipo da sessão (gravados por
+// LoginSessionHandler) e injeta-os no contexto do pedido através das mesmas
+// chaves que RequireAuthGin usa a partir do JWT, para UserIDFromContext/
+// TipoFromContext funcionarem da mesma forma independentemente de qual dos
+// dois mecanismos autenticou o pedido. Pedidos sem sessão válida recebem
+// 401.
+func RequireSessionGin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		session := sessions.Default(c)
+		userID, ok := session.Get(sessionUserIDKey).(int)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"errmsg": "Não autorizado: sessão ausente ou expirada"})
+			return
+		}
+		tipo, _ := session.Get(sessionTipoKey).(string)
+
+		ctx := context.WithValue(c.Request.Context(), userIDContextKey, userID)
+		ctx = context.WithValue(ctx, tipoContextKey, tipo)
+		ctx = logging.AddAttrs(ctx, "user_id", userID)
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"log"
 	"net/url"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -14,9 +16,33 @@ import (
 type Config struct {
 	DBConnectionString    string
 	DBConnectionStringURL string
+	LLMProvider           string // "ollama" (default), "openai" ou "mock"
 	LLMEndpoint           string
 	LLMModel              string
+	LLMAPIKey             string
+	JWTSecret             string
+	JWTTokenTTL           time.Duration // validade dos JWTs emitidos em login/register (default: 24h)
 	Port                  string
+	LogLevel              string // debug|info|warn|error (default: info)
+	Env                   string // "development" (default, texto) ou "production" (JSON)
+	AccessLogFormat       string // "json" (default) ou "combined" (Apache combined log format)
+
+	// QuestionEncryptionKeys, no formato "<key-id>:<chave hex ou base64>,...",
+	// ativa a encriptação em repouso do conteúdo das perguntas quando não
+	// vazio (ver store.AesGcmProcessor). QuestionEncryptionActiveKeyID indica
+	// qual dessas chaves usar para novas gravações.
+	QuestionEncryptionKeys        string
+	QuestionEncryptionActiveKeyID string
+
+	// Sessão de login baseada em cookie (ver auth.NewSessionStore): Secret
+	// assina/encripta o cookie (default: reaproveita o JWTSecret, já que
+	// ambos são apenas segredos do servidor para provar autenticidade).
+	// CookieSecure/CookieHTTPOnly/CookieSameSite controlam as flags do
+	// cookie de sessão emitido em /auth/login.
+	SessionSecret         string
+	SessionCookieSecure   bool
+	SessionCookieHTTPOnly bool
+	SessionCookieSameSite string // "lax" (default), "strict" ou "none"
 }
 
 // LoadConfig lê as variáveis de ambiente e monta a string de conexão
@@ -40,11 +66,92 @@ func LoadConfig() *Config {
 
 	connStrURL := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable",
 		dbUser, dbPassEscaped, dbHost, dbPort, dbName)
+	llmProvider := os.Getenv("LLM_PROVIDER")
+	if llmProvider == "" {
+		llmProvider = "ollama"
+	}
+
+	logLevel := os.Getenv("LOG_LEVEL")
+	if logLevel == "" {
+		logLevel = "info"
+	}
+	env := os.Getenv("APP_ENV")
+	if env == "" {
+		env = "development"
+	}
+
+	accessLogFormat := os.Getenv("ACCESS_LOG_FORMAT")
+	if accessLogFormat == "" {
+		accessLogFormat = "json"
+	}
+
+	jwtTokenTTL := 24 * time.Hour
+	if ttlHoursStr := os.Getenv("JWT_TOKEN_TTL_HOURS"); ttlHoursStr != "" {
+		if ttlHours, err := strconv.Atoi(ttlHoursStr); err == nil && ttlHours > 0 {
+			jwtTokenTTL = time.Duration(ttlHours) * time.Hour
+		} else {
+			log.Printf("Aviso: JWT_TOKEN_TTL_HOURS inválido (%q), usando default de 24h", ttlHoursStr)
+		}
+	}
+
+	questionEncryptionActiveKeyID := os.Getenv("QUESTION_ENCRYPTION_ACTIVE_KEY_ID")
+	if questionEncryptionActiveKeyID == "" {
+		questionEncryptionActiveKeyID = "0"
+	}
+
+	sessionSecret := os.Getenv("SESSION_SECRET")
+	if sessionSecret == "" {
+		sessionSecret = os.Getenv("JWT_SECRET")
+	}
+
+	sessionCookieSecure := env == "production"
+	if secureStr := os.Getenv("SESSION_COOKIE_SECURE"); secureStr != "" {
+		if parsed, err := strconv.ParseBool(secureStr); err == nil {
+			sessionCookieSecure = parsed
+		} else {
+			log.Printf("Aviso: SESSION_COOKIE_SECURE inválido (%q), usando default de %v", secureStr, sessionCookieSecure)
+		}
+	}
+
+	sessionCookieHTTPOnly := true
+	if httpOnlyStr := os.Getenv("SESSION_COOKIE_HTTPONLY"); httpOnlyStr != "" {
+		if parsed, err := strconv.ParseBool(httpOnlyStr); err == nil {
+			sessionCookieHTTPOnly = parsed
+		} else {
+			log.Printf("Aviso: SESSION_COOKIE_HTTPONLY inválido (%q), usando default de %v", httpOnlyStr, sessionCookieHTTPOnly)
+		}
+	}
+
+	sessionCookieSameSite := os.Getenv("SESSION_COOKIE_SAMESITE")
+	if sessionCookieSameSite == "" {
+		sessionCookieSameSite = "lax"
+	}
+	// Browsers já recusam um cookie SameSite=None sem Secure; força aqui para
+	// não depender disso e para o operador não ficar com sessões que nunca
+	// persistem sem nenhum aviso no servidor.
+	if sessionCookieSameSite == "none" && !sessionCookieSecure {
+		log.Printf("Aviso: SESSION_COOKIE_SAMESITE=none exige SESSION_COOKIE_SECURE=true; a forçar Secure")
+		sessionCookieSecure = true
+	}
+
 	return &Config{
-		DBConnectionString:    connStrDSN,
-		DBConnectionStringURL: connStrURL,
-		LLMEndpoint:           os.Getenv("LLM_ENDPOINT"),
-		LLMModel:              os.Getenv("LLM_MODEL"),
-		Port:                  "8080", // Porta que o servidor Go vai ouvir
+		DBConnectionString:            connStrDSN,
+		DBConnectionStringURL:         connStrURL,
+		LLMProvider:                   llmProvider,
+		LLMEndpoint:                   os.Getenv("LLM_ENDPOINT"),
+		LLMModel:                      os.Getenv("LLM_MODEL"),
+		LLMAPIKey:                     os.Getenv("LLM_API_KEY"),
+		JWTSecret:                     os.Getenv("JWT_SECRET"),
+		JWTTokenTTL:                   jwtTokenTTL,
+		Port:                          "8080", // Porta que o servidor Go vai ouvir
+		LogLevel:                      logLevel,
+		Env:                           env,
+		AccessLogFormat:               accessLogFormat,
+		QuestionEncryptionKeys:        os.Getenv("QUESTION_ENCRYPTION_KEYS"),
+		QuestionEncryptionActiveKeyID: questionEncryptionActiveKeyID,
+		SessionSecret:                 sessionSecret,
+		SessionCookieSecure:           sessionCookieSecure,
+		SessionCookieHTTPOnly:         sessionCookieHTTPOnly,
+		SessionCookieSameSite:         sessionCookieSameSite,
 	}
 }
@@ -0,0 +1,33 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// MockProvider lê uma fixture JSON do disco e devolve-a sempre que lhe pedem
+// um quiz. Serve para testes e para correr a aplicação em CI sem depender de
+// um modelo de verdade a correr.
+type MockProvider struct {
+	fixturePath string
+}
+
+// NewMockProvider é o construtor do provider de mock
+func NewMockProvider(fixturePath string) *MockProvider {
+	return &MockProvider{fixturePath: fixturePath}
+}
+
+// GenerateQuiz ignora o prompt e devolve o conteúdo cru da fixture configurada
+func (p *MockProvider) GenerateQuiz(ctx context.Context, prompt string) (string, error) {
+	data, err := os.ReadFile(p.fixturePath)
+	if err != nil {
+		return "", fmt.Errorf("falha ao ler fixture do MockProvider (%s): %w", p.fixturePath, err)
+	}
+	return string(data), nil
+}
+
+// Ping nunca falha, já que não há nenhuma dependência externa envolvida
+func (p *MockProvider) Ping(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,296 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"quizz-core/internal/logging"
+	"quizz-core/internal/metrics"
+	"strings"
+	"time"
+)
+
+// OllamaProvider fala com o endpoint /api/generate de um servidor Ollama.
+type OllamaProvider struct {
+	httpClient *http.Client
+	endpoint   string
+	model      string
+}
+
+// NewOllamaProvider é o construtor do provider Ollama
+func NewOllamaProvider(endpoint, model string) *OllamaProvider {
+	return &OllamaProvider{
+		httpClient: &http.Client{
+			Timeout: 180 * time.Second, // 3-minute timeout
+		},
+		endpoint: endpoint,
+		model:    model,
+	}
+}
+
+// --- Ollama Specific Structs ---
+type OllamaGenerateRequest struct {
+	Model  string `json:"model"`
+	Prompt string `json:"prompt"`
+	Stream bool   `json:"stream"`
+}
+type OllamaGenerateResponse struct {
+	Model     string    `json:"model"`
+	CreatedAt time.Time `json:"created_at"`
+	Response  string    `json:"response"` // This will be a JSON string
+	Done      bool      `json:"done"`
+}
+
+// Ping verifica a conectividade com o servidor LLM (Ollama)
+func (c *OllamaProvider) Ping(ctx context.Context) error {
+	pingClient := http.Client{
+		Timeout: 5 * time.Second, // Timeout curto de 5 segundos
+	}
+	baseURL := strings.Split(c.endpoint, "/api/")[0]
+	if baseURL == "" {
+		baseURL = c.endpoint // Fallback
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", baseURL, nil)
+	if err != nil {
+		return fmt.Errorf("falha ao criar request de ping para LLM: %w", err)
+	}
+	resp, err := pingClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("falha ao enviar ping para LLM: %w", err)
+	}
+	defer resp.Body.Close()
+	return nil
+}
+
+// GenerateQuiz envia o prompt já construído para o Ollama e retorna a string JSON limpa
+func (c *OllamaProvider) GenerateQuiz(ctx context.Context, prompt string) (string, error) {
+
+	logger := logging.FromContext(ctx)
+	start := time.Now()
+	outcome := "ok"
+	defer func() {
+		duration := time.Since(start)
+		metrics.LLMRequestDuration.WithLabelValues("ollama", c.model, outcome).Observe(duration.Seconds())
+		logger.Info("llm_call_completed",
+			"provider", "ollama", "model", c.model, "outcome", outcome, "duration_ms", duration.Milliseconds())
+	}()
+
+	// 1. Create the Ollama-specific payload
+	payload := OllamaGenerateRequest{
+		Model:  c.model,
+		Prompt: prompt,
+		Stream: false,
+	}
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		outcome = "http_error"
+		return "", fmt.Errorf("failed to marshal JSON for Ollama: %w", err)
+	}
+
+	logger.Debug("llm request", "endpoint", c.endpoint, "payload", string(payloadBytes))
+
+	// 2. Send the request
+	req, err := http.NewRequestWithContext(ctx, "POST", c.endpoint, bytes.NewBuffer(payloadBytes))
+	if err != nil {
+		outcome = "http_error"
+		return "", fmt.Errorf("failed to create request for Ollama: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		outcome = "http_error"
+		return "", fmt.Errorf("failed to send request to Ollama: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		outcome = "http_error"
+		bodyBytes, readErr := io.ReadAll(res.Body)
+		if readErr != nil {
+			logger.Error("llm response: status não-OK e falha ao ler body do erro", "status", res.Status, "error", readErr)
+		} else {
+			logger.Error("llm response: status não-OK", "status", res.Status, "body", string(bodyBytes))
+		}
+		return "", fmt.Errorf("Ollama returned non-OK status: %s", res.Status) // Return "" on error
+	}
+
+	// 3. Decode the *Ollama* response
+	var ollamaRes OllamaGenerateResponse
+	if err := json.NewDecoder(res.Body).Decode(&ollamaRes); err != nil {
+		outcome = "decode_error"
+		return "", fmt.Errorf("failed to decode Ollama response: %w", err) // Return "" on error
+	}
+
+	// 4. The JSON we want is INSIDE the 'ollamaRes.Response' string.
+	logger.Debug("llm response: raw string", "response", ollamaRes.Response)
+
+	// --- Bulletproof Cleaning Logic ---
+	jsonString := ollamaRes.Response
+	firstBracket := strings.Index(jsonString, "{")
+	if firstBracket == -1 {
+		outcome = "json_cleanup_error"
+		logger.Error("llm response: JSON não continha um '{'")
+		return "", fmt.Errorf("LLM response did not contain JSON opening bracket") // Return "" on error
+	}
+	lastBracket := strings.LastIndex(jsonString, "}")
+	if lastBracket == -1 {
+		outcome = "json_cleanup_error"
+		logger.Error("llm response: JSON não continha um '}'")
+		return "", fmt.Errorf("LLM response did not contain JSON closing bracket") // Return "" on error
+	}
+	jsonString = jsonString[firstBracket : lastBracket+1]
+	logger.Debug("llm response: cleaned JSON string", "json", jsonString)
+	// --- End Cleaning Logic ---
+
+	// === Attempt to Parse to Verify Structure (including Title) ===
+	// We parse it here to LOG if it fails, but we still return the string
+	var wrappedResponse LLMWrapper // Uses the struct with Title and Questions
+	if err := json.Unmarshal([]byte(jsonString), &wrappedResponse); err != nil {
+		outcome = "json_cleanup_error"
+		metrics.LLMJSONRepairTotal.Inc()
+		logger.Warn("llm response: JSON inválido apesar da limpeza", "json", jsonString, "error", err)
+		// Even if parsing fails here, we return the cleaned string for API1 to try.
+		return jsonString, nil // Return the cleaned string anyway
+	}
+	// If parsing worked, log success and potentially re-encode for safety (optional but safer)
+	logger.Debug("llm response: JSON parse bem-sucedido", "title", wrappedResponse.Title)
+	finalJsonBytes, err := json.Marshal(wrappedResponse)
+	if err != nil {
+		logger.Error("erro ao re-codificar JSON verificado", "error", err)
+		return jsonString, nil // Fallback to original cleaned string
+	}
+	// Return the verified and re-encoded JSON string
+	return string(finalJsonBytes), nil
+	// =================================================================
+
+}
+
+// sendChunk tenta entregar chunk em chunks, desistindo sem bloquear se ctx
+// for cancelado entretanto (o consumidor, ex: a SSE handler, já não vai ler
+// mais nada porque o cliente abortou o streaming). Devolve false nesse caso,
+// para quem chama poder terminar a goroutine em vez de ficar pendurada à
+// espera de um leitor que nunca mais aparece.
+func sendChunk(ctx context.Context, chunks chan<- QuizChunk, chunk QuizChunk) bool {
+	select {
+	case chunks <- chunk:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// GenerateQuizStream liga Stream: true no pedido a Ollama e vai lendo o corpo
+// da resposta linha a linha (NDJSON: um OllamaGenerateResponse por linha).
+// Cada fragmento de 'Response' é acumulado num buffer até encontrarmos um
+// objeto JSON balanceado ('{'/'}' a contar profundidade e a respeitar strings
+// e escapes); nesse momento tentamos validar como LLMWrapper ou, falhando
+// isso, como LLMQuestionResponse, e mandamos o resultado no canal de chunks.
+// Isto permite começar a mostrar perguntas ao utilizador antes de a LLM
+// terminar de gerar o quiz inteiro.
+func (c *OllamaProvider) GenerateQuizStream(ctx context.Context, prompt string) (<-chan QuizChunk, <-chan error) {
+	chunks := make(chan QuizChunk)
+	errs := make(chan error, 1)
+
+	logger := logging.FromContext(ctx)
+
+	go func() {
+		defer close(chunks)
+		defer close(errs)
+
+		payload := OllamaGenerateRequest{
+			Model:  c.model,
+			Prompt: prompt,
+			Stream: true,
+		}
+		payloadBytes, err := json.Marshal(payload)
+		if err != nil {
+			errs <- fmt.Errorf("failed to marshal JSON for Ollama stream: %w", err)
+			return
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", c.endpoint, bytes.NewBuffer(payloadBytes))
+		if err != nil {
+			errs <- fmt.Errorf("failed to create stream request for Ollama: %w", err)
+			return
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := c.httpClient.Do(req)
+		if err != nil {
+			errs <- fmt.Errorf("failed to send stream request to Ollama: %w", err)
+			return
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			bodyBytes, _ := io.ReadAll(res.Body)
+			errs <- fmt.Errorf("Ollama returned non-OK status during stream: %s (body: %s)", res.Status, string(bodyBytes))
+			return
+		}
+
+		var buffer strings.Builder
+		scanner := bufio.NewScanner(res.Body)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+		for scanner.Scan() {
+			select {
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			default:
+			}
+
+			line := scanner.Bytes()
+			if len(line) == 0 {
+				continue
+			}
+
+			var ollamaRes OllamaGenerateResponse
+			if err := json.Unmarshal(line, &ollamaRes); err != nil {
+				logger.Warn("llm stream: linha NDJSON inválida ignorada", "error", err)
+				continue
+			}
+
+			buffer.WriteString(ollamaRes.Response)
+
+			for {
+				jsonObj, rest, ok := extractBalancedJSON(buffer.String())
+				if !ok {
+					break
+				}
+				buffer.Reset()
+				buffer.WriteString(rest)
+
+				var wrapped LLMWrapper
+				if err := json.Unmarshal([]byte(jsonObj), &wrapped); err == nil && wrapped.Title != "" {
+					if !sendChunk(ctx, chunks, QuizChunk{Wrapper: &wrapped}) {
+						return
+					}
+					continue
+				}
+				var question LLMQuestionResponse
+				if err := json.Unmarshal([]byte(jsonObj), &question); err == nil && question.QuestionText != "" {
+					if !sendChunk(ctx, chunks, QuizChunk{Question: &question}) {
+						return
+					}
+				}
+			}
+
+			if ollamaRes.Done {
+				sendChunk(ctx, chunks, QuizChunk{Done: true})
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			errs <- fmt.Errorf("failed reading Ollama stream: %w", err)
+		}
+	}()
+
+	return chunks, errs
+}
@@ -0,0 +1,121 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"quizz-core/internal/logging"
+)
+
+// OpenAIProvider fala com qualquer backend compatível com a API de chat
+// completions da OpenAI (OpenAI, Azure OpenAI, vLLM, etc.).
+type OpenAIProvider struct {
+	httpClient *http.Client
+	endpoint   string // ex: https://api.openai.com/v1/chat/completions
+	model      string
+	apiKey     string
+}
+
+// NewOpenAIProvider é o construtor do provider OpenAI-compatible
+func NewOpenAIProvider(endpoint, model, apiKey string) *OpenAIProvider {
+	return &OpenAIProvider{
+		httpClient: &http.Client{
+			Timeout: 180 * time.Second,
+		},
+		endpoint: endpoint,
+		model:    model,
+		apiKey:   apiKey,
+	}
+}
+
+type openAIChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIResponseFormat struct {
+	Type string `json:"type"`
+}
+
+type openAIChatRequest struct {
+	Model          string                `json:"model"`
+	Messages       []openAIChatMessage   `json:"messages"`
+	ResponseFormat *openAIResponseFormat `json:"response_format,omitempty"`
+}
+
+type openAIChatChoice struct {
+	Message openAIChatMessage `json:"message"`
+}
+
+type openAIChatResponse struct {
+	Choices []openAIChatChoice `json:"choices"`
+}
+
+// GenerateQuiz envia o prompt como mensagem 'user' (com uma mensagem 'system'
+// a reforçar "apenas JSON") e pede response_format json_object.
+func (p *OpenAIProvider) GenerateQuiz(ctx context.Context, prompt string) (string, error) {
+	payload := openAIChatRequest{
+		Model: p.model,
+		Messages: []openAIChatMessage{
+			{Role: "system", Content: "Você responde APENAS com um objeto JSON válido, sem texto adicional."},
+			{Role: "user", Content: prompt},
+		},
+		ResponseFormat: &openAIResponseFormat{Type: "json_object"},
+	}
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JSON for OpenAI: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.endpoint, bytes.NewBuffer(payloadBytes))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request for OpenAI: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	res, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request to OpenAI: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(res.Body)
+		logging.FromContext(ctx).Error("status não-OK recebido da OpenAI", "status", res.Status, "body", string(bodyBytes))
+		return "", fmt.Errorf("OpenAI returned non-OK status: %s", res.Status)
+	}
+
+	var chatRes openAIChatResponse
+	if err := json.NewDecoder(res.Body).Decode(&chatRes); err != nil {
+		return "", fmt.Errorf("failed to decode OpenAI response: %w", err)
+	}
+	if len(chatRes.Choices) == 0 {
+		return "", fmt.Errorf("OpenAI response did not contain any choices")
+	}
+
+	return chatRes.Choices[0].Message.Content, nil
+}
+
+// Ping faz uma chamada curta à lista de modelos para confirmar que a API
+// key e o endpoint estão a responder.
+func (p *OpenAIProvider) Ping(ctx context.Context) error {
+	pingClient := http.Client{Timeout: 5 * time.Second}
+	req, err := http.NewRequestWithContext(ctx, "GET", p.endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("falha ao criar request de ping para OpenAI: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	resp, err := pingClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("falha ao enviar ping para OpenAI: %w", err)
+	}
+	defer resp.Body.Close()
+	return nil
+}
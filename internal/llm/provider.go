@@ -0,0 +1,112 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Provider é a abstração comum a qualquer backend de LLM capaz de gerar um
+// quiz a partir de um prompt já construído. Isto permite trocar Ollama por
+// outro backend (ou por um mock nos testes) sem tocar em QuizService.
+type Provider interface {
+	GenerateQuiz(ctx context.Context, prompt string) (string, error)
+	Ping(ctx context.Context) error
+}
+
+// StreamingProvider é implementado pelos providers que conseguem emitir
+// perguntas parciais à medida que são geradas. Hoje só o Ollama suporta isto;
+// QuizService faz um type assertion para detetar o suporte.
+type StreamingProvider interface {
+	Provider
+	GenerateQuizStream(ctx context.Context, prompt string) (<-chan QuizChunk, <-chan error)
+}
+
+// QuizChunk é uma unidade de trabalho emitida por um StreamingProvider: cada
+// vez que conseguimos extrair um objeto JSON balanceado do buffer
+// acumulado, mandamos ele no canal para quem estiver a ler.
+type QuizChunk struct {
+	Wrapper  *LLMWrapper          // Preenchido quando o chunk é um LLMWrapper completo ({"title":..., "questions":...})
+	Question *LLMQuestionResponse // Preenchido quando o chunk é uma única pergunta
+	Done     bool                 // true no último chunk (o provider sinalizou fim de geração)
+}
+
+// --- Our Expected Output Structs (NÃO USADAS NESTE CENÁRIO POR GenerateQuiz) ---
+type LLMQuestionResponse struct {
+	Subject       string   `json:"subject"`
+	QuestionText  string   `json:"question"`
+	Options       []string `json:"options"`
+	CorrectAnswer string   `json:"correct_answer"`
+	Explanation   string   `json:"explanation"`
+}
+type LLMWrapper struct {
+	Title     string                `json:"title"`
+	Questions []LLMQuestionResponse `json:"questions"`
+}
+
+// BuildQuizPrompt (ajuste o número de perguntas conforme necessário)
+func BuildQuizPrompt(theme string, wrongSubjects []string) string {
+	subjectsStr := strings.Join(wrongSubjects, ", ")
+	return fmt.Sprintf(`
+	Crie um quiz de 1 perguntas sobre o tema principal '%s'.
+	O foco principal do quiz deve ser nestes tópicos: %s.
+
+	REGRAS DE FORMATAÇÃO DA RESPOSTA:
+	1. Retorne APENAS um objeto JSON válido.
+	2. O objeto JSON deve ter DUAS chaves: "title" (string) e "questions" (array). Ex: {"title": "Título", "questions": [...]}.
+	3. Não inclua NENHUM texto antes ou depois do objeto JSON (sem markdown `+"```"+`json).
+	4. Cada objeto no array "questions" deve ter EXATAMENTE os seguintes campos:
+	   - "subject": O tópico específico da pergunta.
+	   - "question": O texto da pergunta.
+	   - "options": Um array de 4 strings com as opções.
+	   - "correct_answer": A string exata da opção correta.
+	   - "explanation": Uma string curta explicando PORQUÊ a resposta correta está certa. <-- NOVA REGRA
+	5. Gere o JSON completo e válido.
+	`, theme, subjectsStr)
+}
+
+// extractBalancedJSON procura, a partir do primeiro '{' em s, o '}' que o
+// fecha respeitando a profundidade de chavetas e ignorando chavetas dentro
+// de strings (e escapes dentro dessas strings). Devolve o objeto JSON
+// encontrado, o restante do buffer depois dele, e se conseguiu encontrar
+// um objeto completo.
+func extractBalancedJSON(s string) (jsonObj string, rest string, ok bool) {
+	start := strings.IndexByte(s, '{')
+	if start == -1 {
+		return "", s, false
+	}
+
+	depth := 0
+	inString := false
+	escaped := false
+
+	for i := start; i < len(s); i++ {
+		ch := s[i]
+
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case ch == '\\':
+				escaped = true
+			case ch == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch ch {
+		case '"':
+			inString = true
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return s[start : i+1], s[i+1:], true
+			}
+		}
+	}
+
+	return "", s, false
+}
@@ -0,0 +1,188 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"quizz-core/internal/logging"
+)
+
+// ErrCircuitOpen é devolvido quando o circuit breaker está aberto e
+// CreateQuiz deve falhar rápido em vez de tentar (e potencialmente
+// bloquear por minutos) a LLM.
+var ErrCircuitOpen = errors.New("circuit breaker aberto: provider de LLM indisponível")
+
+// CircuitState é o estado do circuit breaker
+type CircuitState int
+
+const (
+	CircuitClosed CircuitState = iota
+	CircuitOpen
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// circuitBreaker é um breaker simples: fecha -> abre depois de N falhas
+// consecutivas -> faz uma sondagem (half-open) depois de 'cooldown'.
+type circuitBreaker struct {
+	mu                  sync.Mutex
+	failureThreshold    int
+	cooldown            time.Duration
+	consecutiveFailures int
+	state               CircuitState
+	openedAt            time.Time
+}
+
+func newCircuitBreaker(failureThreshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{failureThreshold: failureThreshold, cooldown: cooldown, state: CircuitClosed}
+}
+
+// allow decide se um novo pedido pode passar, promovendo Open -> HalfOpen
+// quando o cooldown já passou.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitOpen && time.Since(b.openedAt) >= b.cooldown {
+		b.state = CircuitHalfOpen
+	}
+	return b.state != CircuitOpen
+}
+
+func (b *circuitBreaker) recordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.consecutiveFailures = 0
+	b.state = CircuitClosed
+}
+
+func (b *circuitBreaker) recordFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.consecutiveFailures++
+	if b.state == CircuitHalfOpen || b.consecutiveFailures >= b.failureThreshold {
+		b.state = CircuitOpen
+		b.openedAt = time.Now()
+	}
+}
+
+func (b *circuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// ResilientProvider decora qualquer llm.Provider com retry com backoff
+// exponencial e um circuit breaker, para absorver os 5xx/erros de rede/JSON
+// quebrado que a LLM local devolve com frequência.
+type ResilientProvider struct {
+	inner       Provider
+	MaxAttempts int
+	BaseBackoff time.Duration
+	breaker     *circuitBreaker
+}
+
+// NewResilientProvider é o construtor do decorator. maxAttempts default 3 se <= 0.
+func NewResilientProvider(inner Provider, maxAttempts int, baseBackoff time.Duration) *ResilientProvider {
+	if maxAttempts <= 0 {
+		maxAttempts = 3
+	}
+	if baseBackoff <= 0 {
+		baseBackoff = 500 * time.Millisecond
+	}
+	return &ResilientProvider{
+		inner:       inner,
+		MaxAttempts: maxAttempts,
+		BaseBackoff: baseBackoff,
+		breaker:     newCircuitBreaker(5, 30*time.Second), // abre depois de 5 falhas seguidas, sonda após 30s
+	}
+}
+
+// State devolve o estado atual do circuit breaker (usado por CheckHealth)
+func (p *ResilientProvider) State() CircuitState {
+	return p.breaker.State()
+}
+
+// Ping é simplesmente delegado ao provider interno
+func (p *ResilientProvider) Ping(ctx context.Context) error {
+	return p.inner.Ping(ctx)
+}
+
+// GenerateQuiz tenta até MaxAttempts vezes, com backoff exponencial e
+// jitter, e faz fast-fail com ErrCircuitOpen enquanto o breaker está aberto.
+func (p *ResilientProvider) GenerateQuiz(ctx context.Context, prompt string) (string, error) {
+	if !p.breaker.allow() {
+		return "", ErrCircuitOpen
+	}
+
+	var lastErr error
+	currentPrompt := prompt
+
+	for attempt := 0; attempt < p.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			backoff := p.BaseBackoff * time.Duration(1<<uint(attempt-1))
+			jitter := time.Duration(rand.Int63n(int64(p.BaseBackoff) + 1))
+			wait := backoff + jitter
+			logging.FromContext(ctx).Warn("tentativa de geração de quiz falhou, a repetir",
+				"attempt", attempt, "max_attempts", p.MaxAttempts, "error", lastErr, "wait", wait)
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return "", ctx.Err()
+			}
+		}
+
+		result, err := p.inner.GenerateQuiz(ctx, currentPrompt)
+		if err == nil && isValidQuizJSON(result) {
+			p.breaker.recordSuccess()
+			return result, nil
+		}
+
+		if err == nil {
+			// A LLM devolveu 200 mas prosa em vez de JSON: re-prompt mais rígido.
+			lastErr = fmt.Errorf("LLM devolveu conteúdo que não é um JSON de quiz válido")
+			currentPrompt = prompt + "\n\nIMPORTANTE: responda APENAS com o objeto JSON pedido, nada mais."
+		} else {
+			lastErr = err
+		}
+	}
+
+	p.breaker.recordFailure()
+	return "", fmt.Errorf("falha ao gerar quiz após %d tentativas: %w", p.MaxAttempts, lastErr)
+}
+
+// GenerateQuizStream delega diretamente ao provider interno quando ele
+// suporta streaming: o protocolo de retry acima não faz sentido para um
+// canal que já começou a emitir perguntas parciais ao cliente.
+func (p *ResilientProvider) GenerateQuizStream(ctx context.Context, prompt string) (<-chan QuizChunk, <-chan error) {
+	streamer, ok := p.inner.(StreamingProvider)
+	if !ok {
+		errs := make(chan error, 1)
+		errs <- fmt.Errorf("o provider de LLM configurado não suporta streaming")
+		close(errs)
+		chunks := make(chan QuizChunk)
+		close(chunks)
+		return chunks, errs
+	}
+	return streamer.GenerateQuizStream(ctx, prompt)
+}
+
+func isValidQuizJSON(raw string) bool {
+	var wrapped LLMWrapper
+	return json.Unmarshal([]byte(raw), &wrapped) == nil && wrapped.Title != ""
+}
@@ -0,0 +1,65 @@
+// Package logging centraliza a configuração do log/slog da aplicação:
+// nível configurável, JSON em produção / texto em desenvolvimento, e um
+// logger por-pedido propagado via contexto com request_id/method/path/user_id.
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+type ctxKey string
+
+const loggerCtxKey ctxKey = "logging_logger"
+
+// New constrói o logger raiz da aplicação a partir do nível (debug|info|warn|error)
+// e do ambiente ("production" => JSON, qualquer outro => texto).
+func New(level, env string) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: parseLevel(level)}
+
+	var handler slog.Handler
+	if strings.EqualFold(env, "production") {
+		handler = slog.NewJSONHandler(os.Stdout, opts)
+	} else {
+		handler = slog.NewTextHandler(os.Stdout, opts)
+	}
+
+	return slog.New(handler)
+}
+
+func parseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// WithContext guarda um logger já enriquecido (request_id, method, path, ...)
+// no contexto, para os níveis abaixo (service/store/llm) o recuperarem.
+func WithContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerCtxKey, logger)
+}
+
+// FromContext recupera o logger guardado no contexto, ou o logger default
+// do slog se nenhum tiver sido guardado (ex: chamadas fora de um pedido HTTP).
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerCtxKey).(*slog.Logger); ok && logger != nil {
+		return logger
+	}
+	return slog.Default()
+}
+
+// AddAttrs enriquece o logger já guardado no contexto com atributos
+// adicionais (ex: user_id, resolvido depois da autenticação) e guarda o
+// resultado de volta no contexto.
+func AddAttrs(ctx context.Context, args ...any) context.Context {
+	return WithContext(ctx, FromContext(ctx).With(args...))
+}
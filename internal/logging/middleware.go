@@ -0,0 +1,96 @@
+package logging
+
+import (
+	"crypto/rand"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// GinMiddleware garante um request ID (gera um se o cliente não mandar),
+// guarda no contexto um *slog.Logger já pré-populado com
+// request_id/method/path, e emite uma linha de access log por pedido. Deve
+// ser o primeiro middleware registado no engine (gin.Engine.Use): camadas
+// internas (ex: auth.RequireAuthGin) podem enriquecer ainda mais este logger
+// via AddAttrs assim que resolverem dados próprios (ex: user_id). Usa
+// c.Writer.Status()/Size(), que o próprio Gin já expõe, para saber o que o
+// handler escreveu.
+//
+// accessLogFormat controla o formato da linha de access log: "json" (default)
+// emite os mesmos campos estruturados via slog; "combined" emite o formato
+// combinado do Apache, para ambientes com parsers já configurados para ele.
+func GinMiddleware(base *slog.Logger, accessLogFormat string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		r := c.Request
+		requestID := r.Header.Get(requestIDHeader)
+		if requestID == "" {
+			requestID = generateRequestID()
+		}
+		c.Header(requestIDHeader, requestID)
+
+		logger := base.With(
+			"request_id", requestID,
+			"method", r.Method,
+			"path", r.URL.Path,
+		)
+
+		ctx := WithContext(r.Context(), logger)
+		c.Request = r.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		latency := time.Since(start)
+
+		if accessLogFormat == "combined" {
+			logAccessCombined(r, requestID, status, c.Writer.Size(), start)
+			return
+		}
+		logger.Info("access_log",
+			"status", status,
+			"bytes", c.Writer.Size(),
+			"latency_ms", latency.Milliseconds(),
+			"remote_addr", r.RemoteAddr,
+		)
+	}
+}
+
+// logAccessCombined escreve uma linha no formato combinado do Apache:
+// remote_addr - - [timestamp] "METHOD path proto" status bytes "referer" "user-agent".
+// O request_id vai no fim como um campo extra, já que o formato combinado
+// clássico não tem espaço para ele.
+func logAccessCombined(r *http.Request, requestID string, status, bytes int, at time.Time) {
+	referer := r.Referer()
+	if referer == "" {
+		referer = "-"
+	}
+	userAgent := r.UserAgent()
+	if userAgent == "" {
+		userAgent = "-"
+	}
+	fmt.Printf("%s - - [%s] %q %d %d %q %q request_id=%s\n",
+		r.RemoteAddr,
+		at.Format("02/Jan/2006:15:04:05 -0700"),
+		fmt.Sprintf("%s %s %s", r.Method, r.URL.RequestURI(), r.Proto),
+		status, bytes, referer, userAgent, requestID,
+	)
+}
+
+// generateRequestID gera um ID aleatório no formato de um UUIDv4. Os bits de
+// versão/variante são fixados para parecer um UUID válido a ferramentas de
+// log, mas isto não é uma implementação RFC 4122 completa (não há essa
+// dependência neste projeto).
+func generateRequestID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "unknown"
+	}
+	buf[6] = (buf[6] & 0x0f) | 0x40
+	buf[8] = (buf[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", buf[0:4], buf[4:6], buf[6:8], buf[8:10], buf[10:16])
+}
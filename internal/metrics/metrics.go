@@ -0,0 +1,68 @@
+// Package metrics expõe as métricas Prometheus da aplicação e o handler
+// /metrics (promhttp).
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	// LLMRequestDuration mede quanto tempo demora cada chamada ao provider de LLM
+	LLMRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "llm_request_duration_seconds",
+		Help:    "Duração das chamadas ao provider de LLM, por provider/modelo/outcome.",
+		Buckets: prometheus.ExponentialBuckets(0.1, 2, 12), // 0.1s .. ~200s
+	}, []string{"provider", "model", "outcome"})
+
+	// LLMJSONRepairTotal conta quantas vezes a limpeza de colchetes foi usada
+	// mas o json.Unmarshal continuou a falhar (LLM devolveu prosa em vez de JSON).
+	LLMJSONRepairTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "llm_json_repair_total",
+		Help: "Número de respostas da LLM que precisaram de limpeza de JSON e mesmo assim falharam ao fazer parse.",
+	})
+
+	// DBOpenConnections/DBInUse/DBIdle refletem sqlx.DB.Stats()
+	DBOpenConnections = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "db_open_connections",
+		Help: "Número de conexões abertas ao banco de dados (sql.DBStats.OpenConnections).",
+	})
+	DBInUse = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "db_in_use",
+		Help: "Número de conexões ao banco de dados atualmente em uso (sql.DBStats.InUse).",
+	})
+	DBIdle = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "db_idle",
+		Help: "Número de conexões ao banco de dados ociosas (sql.DBStats.Idle).",
+	})
+
+	// QuizSubmissionScore regista a distribuição das pontuações dos quizzes submetidos
+	QuizSubmissionScore = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "quiz_submission_score",
+		Help:    "Distribuição das pontuações (0-100) das submissões de quiz.",
+		Buckets: prometheus.LinearBuckets(0, 10, 11), // 0,10,...,100
+	})
+
+	// HealthCheckDependencyUp espelha o resultado de CheckHealth para cada dependência
+	HealthCheckDependencyUp = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "health_check_dependency_up",
+		Help: "1 se a dependência (database|llm) está UP no último health check, 0 caso contrário.",
+	}, []string{"dep"})
+)
+
+// Handler devolve o http.Handler do endpoint /metrics
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// SetDependencyUp traduz um booleano de saúde para o valor 0/1 do gauge
+func SetDependencyUp(dep string, up bool) {
+	value := 0.0
+	if up {
+		value = 1.0
+	}
+	HealthCheckDependencyUp.WithLabelValues(dep).Set(value)
+}
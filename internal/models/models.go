@@ -27,10 +27,11 @@ type Tema struct {
 
 // Quiz (from 'quizzes' table)
 type Quiz struct {
-	ID     int    `json:"id" db:"id"`
-	Nome   string `json:"nome" db:"nome"`
-	TemaID int    `json:"tema_id" db:"tema_id"`
-	Ativo  bool   `json:"ativo" db:"ativo"`
+	ID      int       `json:"id" db:"id"`
+	Nome    string    `json:"nome" db:"nome"`
+	TemaID  int       `json:"tema_id" db:"tema_id"`
+	Ativo   bool      `json:"ativo" db:"ativo"`
+	Criacao time.Time `json:"criacao" db:"criacao"`
 }
 
 // Pergunta (from 'perguntas' table)
@@ -77,11 +78,15 @@ type RespostaDada struct {
 
 // --- API Structs (What comes in and what goes out) ---
 
-// CreateQuizRequest is what your API will receive from the other API
+// CreateQuizRequest is what your API will receive from the other API.
+// UserID nunca vem do corpo do pedido: o handler preenche-o a partir do
+// subject autenticado (JWT), para o chamador não conseguir gerar um quiz
+// em nome de outro utilizador.
 type CreateQuizRequest struct {
-	UserID        string   `json:"user_id"`        // Using string as requested
-	Theme         string   `json:"theme"`          // e.g., "Physics"
-	WrongSubjects []string `json:"wrong_subjects"` // The 5 subjects
+	UserID        string   `json:"-"`
+	Theme         string   `json:"theme"`              // e.g., "Physics"
+	WrongSubjects []string `json:"wrong_subjects"`     // The 5 subjects
+	PaperID       string   `json:"paper_id,omitempty"` // Se presente, instancia este quiz_paper em vez de chamar a LLM
 }
 
 // QuizAPIResponse is what you will return
@@ -99,10 +104,17 @@ type QuestionAPI struct {
 	Options  []string `json:"options"`  // The options
 }
 
+// SubmissionRequest é o payload de submissão de respostas. Tal como em
+// CreateQuizRequest, UserID não vem do corpo: o handler preenche-o a partir
+// do subject autenticado (JWT). IdempotencyKey/RequestHash também não vêm
+// do corpo: o handler preenche-os a partir do header 'Idempotency-Key' e do
+// hash do corpo recebido, para detectar retries duplicados.
 type SubmissionRequest struct {
-	QuizID  string       `json:"quiz_id"`
-	UserID  string       `json:"user_id"` // Usando string para consistência
-	Answers []UserAnswer `json:"answers"`
+	QuizID         string       `json:"quiz_id"`
+	UserID         string       `json:"-"`
+	Answers        []UserAnswer `json:"answers"`
+	IdempotencyKey string       `json:"-"`
+	RequestHash    string       `json:"-"`
 }
 
 // UserAnswer é a resposta de uma única pergunta
@@ -111,6 +123,36 @@ type UserAnswer struct {
 	SelectedOption string `json:"selected_option"` // O *texto* da opção que o usuário escolheu
 }
 
+// DraftRequest é o payload de PATCH .../quiz/draft: respostas parciais de um
+// quiz que o utilizador ainda não submeteu definitivamente. Tal como em
+// SubmissionRequest, UserID não vem do corpo.
+type DraftRequest struct {
+	QuizID  string       `json:"quiz_id"`
+	UserID  string       `json:"-"`
+	Answers []UserAnswer `json:"answers"`
+}
+
+// IdempotencyInfo carrega a chave e o hash do pedido quando o cliente envia
+// o header 'Idempotency-Key' em POST /api/v1/quiz/submit, para que o
+// repositório possa gravar o registo de replay na mesma transação que salva
+// a submissão.
+type IdempotencyInfo struct {
+	Key         string
+	RequestHash string
+}
+
+// IdempotencyRecord é o que fica gravado em 'submission_idempotency' depois
+// de uma submissão bem-sucedida feita com um header 'Idempotency-Key'.
+type IdempotencyRecord struct {
+	ID             int       `db:"id"`
+	IdempotencyKey string    `db:"idempotency_key"`
+	UtilizadorID   int       `db:"utilizador_id"`
+	QuizzID        int       `db:"quizz_id"`
+	SubmissaoID    int       `db:"submissao_id"`
+	RequestHash    string    `db:"request_hash"`
+	Criacao        time.Time `db:"criacao"`
+}
+
 // SubmissionResponse é o que retornamos após a submissão
 type SubmissionResponse struct {
 	SubmissionID int     `json:"submission_id"`
@@ -165,11 +207,48 @@ type AnswerOptionDetail struct {
 	Corpo      string `json:"corpo"`
 }
 
+// RegisterRequest é o payload de POST /auth/register. O auto-registo nunca
+// aceita 'tipo' do chamador (sempre cai no default da tabela, "aluno"); só
+// um admin pode criar contas com outro tipo, via AdminCreateUserRequest.
+type RegisterRequest struct {
+	Nome     string `json:"nome"`
+	Password string `json:"password"`
+}
+
+// LoginRequest é o payload de POST /auth/login
+type LoginRequest struct {
+	Nome     string `json:"nome"`
+	Password string `json:"password"`
+}
+
+// LoginResponse é devolvido por register/login com o JWT emitido
+type LoginResponse struct {
+	Token  string `json:"token"`
+	UserID string `json:"user_id"`
+}
+
+// AdminCreateUserRequest é o payload de POST /api/v1/admin/users (admin-only):
+// a password inicial é gerada pelo servidor, não enviada pelo chamador.
+type AdminCreateUserRequest struct {
+	Nome string `json:"nome"`
+	Tipo string `json:"tipo"`
+}
+
+// AdminCreateUserResponse devolve a password inicial gerada para o admin
+// poder entregá-la ao novo utilizador (ela não fica disponível depois disto).
+type AdminCreateUserResponse struct {
+	UserID          string `json:"user_id"`
+	Nome            string `json:"nome"`
+	Tipo            string `json:"tipo"`
+	InitialPassword string `json:"initial_password"`
+}
+
 type HealthStatus string
 
 const (
-	StatusUp   HealthStatus = "UP"
-	StatusDown HealthStatus = "DOWN"
+	StatusUp       HealthStatus = "UP"
+	StatusDown     HealthStatus = "DOWN"
+	StatusDegraded HealthStatus = "DEGRADED"
 )
 
 // HealthResponse é a resposta detalhada do endpoint /health
@@ -181,3 +260,124 @@ type RawQuizResponse struct {
 	UserID     string `json:"user_id"`      // O ID do utilizador que pediu
 	RawLLMJson string `json:"raw_llm_json"` // A string JSON crua vinda da LLM
 }
+
+// Pagination agrupa os parâmetros comuns de paginação/busca/ordenação
+// aceites pelos endpoints de listagem via query string
+// (?q=...&page=1&limit=20&sort=-criacao, onde o prefixo '-' inverte a
+// ordenação). Page e Limit já vêm normalizados pelo handler antes de chegar
+// ao repositório (ver store.NormalizePagination).
+type Pagination struct {
+	Page  int
+	Limit int
+	Q     string
+	Sort  string
+}
+
+// PagedResponse envolve qualquer lista paginada com os metadados que o
+// cliente precisa para montar a paginação.
+type PagedResponse[T any] struct {
+	Items      []T `json:"items"`
+	Total      int `json:"total"`
+	Page       int `json:"page"`
+	Limit      int `json:"limit"`
+	TotalPages int `json:"total_pages"`
+}
+
+// QuizFilter agrupa os filtros suportados por quiz.Repository.ListQuizzes.
+type QuizFilter struct {
+	Pagination
+	ThemeID      *int
+	NomeContains string
+	Ativo        *bool
+	CreatedAfter *time.Time
+}
+
+// QuizPaper (from 'quiz_papers' table) é um "template" reutilizável de quiz:
+// um conjunto versionado de perguntas já existentes que pode ser instanciado
+// várias vezes em quizzes distintos, em vez de cada geração criar perguntas
+// novas. QuestionCount é recalculado sempre que uma pergunta é adicionada ou
+// removida (ver paper.Repository.recomputeQuestionCount).
+type QuizPaper struct {
+	ID            int       `json:"id" db:"id"`
+	Title         string    `json:"title" db:"title"`
+	ThemeID       int       `json:"theme_id" db:"theme_id"`
+	QuestionCount int       `json:"question_count" db:"question_count"`
+	Version       int       `json:"version" db:"version"`
+	CreatedBy     int       `json:"created_by" db:"created_by"`
+	Ativo         bool      `json:"ativo" db:"ativo"`
+	Criacao       time.Time `json:"criacao" db:"criacao"`
+}
+
+// QuizPaperQuestion (from 'quiz_paper_questions' table) liga um QuizPaper a
+// uma Pergunta já existente, na posição em que deve aparecer quando o paper
+// for instanciado.
+type QuizPaperQuestion struct {
+	ID          int `json:"id" db:"id"`
+	QuizPaperID int `json:"quiz_paper_id" db:"quiz_paper_id"`
+	PerguntaID  int `json:"pergunta_id" db:"pergunta_id"`
+	Posicao     int `json:"posicao" db:"posicao"`
+}
+
+// CreatePaperRequest é o payload de POST /api/v1/papers. CreatedBy nunca vem
+// do corpo: o handler preenche-o a partir do subject autenticado (JWT), tal
+// como UserID em CreateQuizRequest.
+type CreatePaperRequest struct {
+	Title       string `json:"title"`
+	ThemeID     int    `json:"theme_id"`
+	PerguntaIDs []int  `json:"pergunta_ids"`
+	CreatedBy   int    `json:"-"`
+}
+
+// UpdatePaperRequest é o payload de PUT /api/v1/papers/{id}: substitui o
+// título e/ou o conjunto de perguntas do paper, incrementando a versão.
+type UpdatePaperRequest struct {
+	Title       string `json:"title"`
+	PerguntaIDs []int  `json:"pergunta_ids"`
+}
+
+// InstantiatePaperRequest é o payload de POST /api/v1/papers/{id}/instantiate.
+// UserID nunca vem do corpo: o handler preenche-o a partir do subject
+// autenticado, porque é ele quem vai receber o quiz materializado.
+type InstantiatePaperRequest struct {
+	UserID string `json:"-"`
+}
+
+// QAComment (from 'qa_comments' table) é um comentário de pergunta-e-resposta
+// sobre um quiz ou uma submissão (nunca ambos — ver a CHECK constraint da
+// tabela). ParentID liga um comentário à resposta que ele responde, para
+// threading; um comentário de topo tem ParentID nulo.
+type QAComment struct {
+	ID           int       `json:"id" db:"id"`
+	QuizzID      *int      `json:"quizz_id,omitempty" db:"quizz_id"`
+	SubmissaoID  *int      `json:"submissao_id,omitempty" db:"submissao_id"`
+	UtilizadorID int       `json:"utilizador_id" db:"utilizador_id"`
+	ParentID     *int      `json:"parent_id,omitempty" db:"parent_id"`
+	Corpo        string    `json:"corpo" db:"corpo"`
+	Criacao      time.Time `json:"criacao" db:"criacao"`
+}
+
+// CreateCommentRequest é o payload de POST .../comments. UtilizadorID nunca
+// vem do corpo: o handler preenche-o a partir do subject autenticado, tal
+// como CreatedBy em CreatePaperRequest.
+type CreateCommentRequest struct {
+	Corpo        string `json:"corpo"`
+	ParentID     *int   `json:"parent_id"`
+	UtilizadorID int    `json:"-"`
+}
+
+// CommentCursorPage é o par limit/cursor que os endpoints de comentários
+// aceitam na query string (?limit=&cursor=). Ao contrário de Pagination
+// (baseada em page), o cursor é o id do último comentário já visto pelo
+// cliente, porque novos comentários chegam continuamente e uma página por
+// número ficaria instável. Cursor 0 pede a primeira página.
+type CommentCursorPage struct {
+	Limit  int
+	Cursor int
+}
+
+// CommentPage envolve uma página de comentários com o cursor a usar no
+// próximo pedido; NextCursor vem a 0 quando não há mais comentários.
+type CommentPage struct {
+	Items      []QAComment `json:"items"`
+	NextCursor int         `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,153 @@
+// Package comment define o repositório de comentários de QA (QAComment)
+// sobre quizzes e submissões, e a sua implementação Postgres.
+package comment
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"quizz-core/internal/models"
+)
+
+// Repository é a interface que o usecase layer depende, para poder ser
+// trocada por outra implementação (ou um mock) em testes.
+type Repository interface {
+	ListByQuiz(ctx context.Context, quizID int, page models.CommentCursorPage) ([]models.QAComment, error)
+	ListBySubmission(ctx context.Context, submissionID int, page models.CommentCursorPage) ([]models.QAComment, error)
+	CreateForQuiz(ctx context.Context, quizID int, req models.CreateCommentRequest) (*models.QAComment, error)
+	CreateForSubmission(ctx context.Context, submissionID int, req models.CreateCommentRequest) (*models.QAComment, error)
+	Get(ctx context.Context, id int) (*models.QAComment, error)
+	Delete(ctx context.Context, id int) error
+}
+
+// PostgresRepository implementa Repository contra uma base de dados Postgres.
+type PostgresRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresRepository é o construtor da implementação Postgres
+func NewPostgresRepository(db *sqlx.DB) *PostgresRepository {
+	return &PostgresRepository{db: db}
+}
+
+// ListByQuiz lista os comentários de um quiz por ordem crescente de id,
+// começando depois de page.Cursor (0 pede a primeira página).
+func (r *PostgresRepository) ListByQuiz(ctx context.Context, quizID int, page models.CommentCursorPage) ([]models.QAComment, error) {
+	var comments []models.QAComment
+	err := r.db.SelectContext(ctx, &comments,
+		`SELECT * FROM qa_comments WHERE quizz_id = $1 AND id > $2 ORDER BY id ASC LIMIT $3`,
+		quizID, page.Cursor, page.Limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar comentários do quiz %d: %w", quizID, err)
+	}
+	return comments, nil
+}
+
+// ListBySubmission lista os comentários de uma submissão por ordem crescente
+// de id, começando depois de page.Cursor (0 pede a primeira página).
+func (r *PostgresRepository) ListBySubmission(ctx context.Context, submissionID int, page models.CommentCursorPage) ([]models.QAComment, error) {
+	var comments []models.QAComment
+	err := r.db.SelectContext(ctx, &comments,
+		`SELECT * FROM qa_comments WHERE submissao_id = $1 AND id > $2 ORDER BY id ASC LIMIT $3`,
+		submissionID, page.Cursor, page.Limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar comentários da submissão %d: %w", submissionID, err)
+	}
+	return comments, nil
+}
+
+// CreateForQuiz cria um comentário ligado a um quiz. Se req.ParentID vier
+// preenchido, verifica antes que esse comentário-pai existe e já pertence ao
+// mesmo quiz, para não se poder criar uma resposta "órfã" apontando para um
+// comentário de outro quiz/submissão.
+func (r *PostgresRepository) CreateForQuiz(ctx context.Context, quizID int, req models.CreateCommentRequest) (*models.QAComment, error) {
+	var quizExists bool
+	if err := r.db.GetContext(ctx, &quizExists, "SELECT EXISTS(SELECT 1 FROM quizzes WHERE id = $1)", quizID); err != nil {
+		return nil, fmt.Errorf("falha ao verificar existência do quiz %d: %w", quizID, err)
+	}
+	if !quizExists {
+		return nil, sql.ErrNoRows
+	}
+	if err := r.checkParent(ctx, req.ParentID, "quizz_id", quizID); err != nil {
+		return nil, err
+	}
+
+	var comment models.QAComment
+	err := r.db.GetContext(ctx, &comment,
+		`INSERT INTO qa_comments (quizz_id, utilizador_id, parent_id, corpo) VALUES ($1, $2, $3, $4) RETURNING *`,
+		quizID, req.UtilizadorID, req.ParentID, req.Corpo,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao inserir comentário do quiz %d: %w", quizID, err)
+	}
+	return &comment, nil
+}
+
+// CreateForSubmission cria um comentário ligado a uma submissão, com a mesma
+// validação de parentesco que CreateForQuiz.
+func (r *PostgresRepository) CreateForSubmission(ctx context.Context, submissionID int, req models.CreateCommentRequest) (*models.QAComment, error) {
+	var submissionExists bool
+	if err := r.db.GetContext(ctx, &submissionExists, "SELECT EXISTS(SELECT 1 FROM submissao WHERE id = $1)", submissionID); err != nil {
+		return nil, fmt.Errorf("falha ao verificar existência da submissão %d: %w", submissionID, err)
+	}
+	if !submissionExists {
+		return nil, sql.ErrNoRows
+	}
+	if err := r.checkParent(ctx, req.ParentID, "submissao_id", submissionID); err != nil {
+		return nil, err
+	}
+
+	var comment models.QAComment
+	err := r.db.GetContext(ctx, &comment,
+		`INSERT INTO qa_comments (submissao_id, utilizador_id, parent_id, corpo) VALUES ($1, $2, $3, $4) RETURNING *`,
+		submissionID, req.UtilizadorID, req.ParentID, req.Corpo,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao inserir comentário da submissão %d: %w", submissionID, err)
+	}
+	return &comment, nil
+}
+
+// checkParent confirma que, se parentID vier preenchido, esse comentário já
+// existe e pertence ao mesmo quiz/submissão (coluna parentColumn = parentValue).
+func (r *PostgresRepository) checkParent(ctx context.Context, parentID *int, parentColumn string, parentValue int) error {
+	if parentID == nil {
+		return nil
+	}
+	var parentMatches bool
+	query := fmt.Sprintf("SELECT EXISTS(SELECT 1 FROM qa_comments WHERE id = $1 AND %s = $2)", parentColumn)
+	if err := r.db.GetContext(ctx, &parentMatches, query, *parentID, parentValue); err != nil {
+		return fmt.Errorf("falha ao verificar comentário-pai %d: %w", *parentID, err)
+	}
+	if !parentMatches {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// Get busca um comentário pelo id.
+func (r *PostgresRepository) Get(ctx context.Context, id int) (*models.QAComment, error) {
+	var comment models.QAComment
+	err := r.db.GetContext(ctx, &comment, "SELECT * FROM qa_comments WHERE id = $1", id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("falha ao buscar comentário %d: %w", id, err)
+	}
+	return &comment, nil
+}
+
+// Delete apaga um comentário definitivamente (ao contrário de quizzes/papers,
+// comentários não têm soft-delete: não há caso de uso para os reativar).
+func (r *PostgresRepository) Delete(ctx context.Context, id int) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM qa_comments WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("falha ao apagar comentário %d: %w", id, err)
+	}
+	return nil
+}
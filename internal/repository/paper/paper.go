@@ -0,0 +1,294 @@
+// Package paper define o repositório de quiz-papers (Repository) e a sua
+// implementação Postgres. Um quiz_paper é um "template" versionado de
+// perguntas já existentes (um banco de questões reutilizável); um Quiz é uma
+// instância runnable derivada de uma versão de um paper, materializada por
+// InstantiatePaper.
+package paper
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"quizz-core/internal/models"
+	"quizz-core/internal/store"
+)
+
+// ErrNoQuestions é devolvido por CreatePaper/UpdatePaper quando a lista de
+// pergunta_ids vem vazia: um paper sem perguntas não pode ser instanciado.
+var ErrNoQuestions = errors.New("quiz paper sem perguntas")
+
+// Repository é a interface que o usecase layer depende, para poder ser
+// trocada por outra implementação (ou um mock) em testes.
+type Repository interface {
+	CreatePaper(ctx context.Context, req models.CreatePaperRequest) (*models.QuizPaper, error)
+	GetPaper(ctx context.Context, id int) (*models.QuizPaper, []models.QuizPaperQuestion, error)
+	UpdatePaper(ctx context.Context, id int, req models.UpdatePaperRequest) (*models.QuizPaper, error)
+	DeletePaper(ctx context.Context, id int) (int64, error)
+	InstantiatePaper(ctx context.Context, paperID, userID int) (*models.Quiz, []models.Pergunta, error)
+}
+
+// PostgresRepository implementa Repository contra uma base de dados Postgres.
+type PostgresRepository struct {
+	db        *sqlx.DB
+	processor store.QuestionContentProcessor
+}
+
+// NewPostgresRepository é o construtor da implementação Postgres. processor
+// transforma o conteúdo das perguntas antes de gravar/depois de ler (ver
+// store.QuestionContentProcessor); passe store.NoOpContentProcessor{} quando
+// a encriptação em repouso não estiver configurada.
+func NewPostgresRepository(db *sqlx.DB, processor store.QuestionContentProcessor) *PostgresRepository {
+	return &PostgresRepository{db: db, processor: processor}
+}
+
+// CreatePaper cria um quiz_paper na versão 1 e já liga as perguntas indicadas
+// (que têm de já existir em 'perguntas'), na ordem em que vêm em PerguntaIDs.
+func (r *PostgresRepository) CreatePaper(ctx context.Context, req models.CreatePaperRequest) (*models.QuizPaper, error) {
+	if len(req.PerguntaIDs) == 0 {
+		return nil, ErrNoQuestions
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao iniciar transação de criação de paper: %w", err)
+	}
+	defer tx.Rollback()
+
+	var paper models.QuizPaper
+	err = tx.GetContext(ctx, &paper,
+		`INSERT INTO quiz_papers (title, theme_id, created_by) VALUES ($1, $2, $3) RETURNING *`,
+		req.Title, req.ThemeID, req.CreatedBy,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao inserir quiz paper: %w", err)
+	}
+
+	if err := linkQuestions(ctx, tx, paper.ID, req.PerguntaIDs); err != nil {
+		return nil, err
+	}
+
+	if err := recomputeQuestionCount(ctx, tx, paper.ID, &paper.QuestionCount); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("falha ao commitar transação de criação de paper: %w", err)
+	}
+
+	return &paper, nil
+}
+
+// GetPaper busca um quiz_paper ativo pelo id, com as perguntas ligadas a ele
+// na ordem de 'posicao'.
+func (r *PostgresRepository) GetPaper(ctx context.Context, id int) (*models.QuizPaper, []models.QuizPaperQuestion, error) {
+	var paper models.QuizPaper
+	err := r.db.GetContext(ctx, &paper, "SELECT * FROM quiz_papers WHERE id = $1 AND ativo = TRUE", id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil, sql.ErrNoRows
+		}
+		return nil, nil, fmt.Errorf("falha ao buscar quiz paper %d: %w", id, err)
+	}
+
+	var questions []models.QuizPaperQuestion
+	err = r.db.SelectContext(ctx, &questions,
+		"SELECT * FROM quiz_paper_questions WHERE quiz_paper_id = $1 ORDER BY posicao ASC", id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("falha ao buscar perguntas do paper %d: %w", id, err)
+	}
+
+	return &paper, questions, nil
+}
+
+// UpdatePaper substitui o título e/ou o conjunto de perguntas de um paper
+// ativo, incrementando a versão sempre que chamado (mesmo que só o título
+// mude), porque o snapshot de perguntas que qualquer instância aponta deve
+// poder ser rastreado até uma versão concreta.
+func (r *PostgresRepository) UpdatePaper(ctx context.Context, id int, req models.UpdatePaperRequest) (*models.QuizPaper, error) {
+	if len(req.PerguntaIDs) == 0 {
+		return nil, ErrNoQuestions
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao iniciar transação de atualização de paper: %w", err)
+	}
+	defer tx.Rollback()
+
+	var paper models.QuizPaper
+	err = tx.GetContext(ctx, &paper,
+		`UPDATE quiz_papers SET title = $1, version = version + 1 WHERE id = $2 AND ativo = TRUE RETURNING *`,
+		req.Title, id,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("falha ao atualizar quiz paper %d: %w", id, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM quiz_paper_questions WHERE quiz_paper_id = $1", id); err != nil {
+		return nil, fmt.Errorf("falha ao limpar perguntas antigas do paper %d: %w", id, err)
+	}
+	if err := linkQuestions(ctx, tx, id, req.PerguntaIDs); err != nil {
+		return nil, err
+	}
+	if err := recomputeQuestionCount(ctx, tx, id, &paper.QuestionCount); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("falha ao commitar transação de atualização de paper: %w", err)
+	}
+
+	return &paper, nil
+}
+
+// DeletePaper faz um "soft-delete" de um paper, definindo ativo = FALSE
+// (tal como quiz.Repository.DeactivateQuiz para quizzes).
+func (r *PostgresRepository) DeletePaper(ctx context.Context, id int) (int64, error) {
+	result, err := r.db.ExecContext(ctx, "UPDATE quiz_papers SET ativo = FALSE WHERE id = $1 AND ativo = TRUE", id)
+	if err != nil {
+		return 0, fmt.Errorf("falha ao executar update para desativar paper: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("falha ao verificar linhas afetadas: %w", err)
+	}
+	return rowsAffected, nil
+}
+
+// InstantiatePaper materializa um Quiz runnable a partir da versão atual de
+// um paper: cria uma nova linha em 'quizzes' ligada ao paper, e copia cada
+// pergunta (e as suas respostas) do banco de questões para essa instância,
+// para o quiz instanciado ter as suas próprias linhas em 'perguntas'/
+// 'respostas' e não partilhar o id de pergunta com o banco reutilizável.
+func (r *PostgresRepository) InstantiatePaper(ctx context.Context, paperID, userID int) (*models.Quiz, []models.Pergunta, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("falha ao iniciar transação de instanciação de paper: %w", err)
+	}
+	defer tx.Rollback()
+
+	var paper models.QuizPaper
+	err = tx.GetContext(ctx, &paper, "SELECT * FROM quiz_papers WHERE id = $1 AND ativo = TRUE", paperID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil, sql.ErrNoRows
+		}
+		return nil, nil, fmt.Errorf("falha ao buscar quiz paper %d: %w", paperID, err)
+	}
+
+	var tema models.Tema
+	if err := tx.GetContext(ctx, &tema, "SELECT * FROM tema WHERE id = $1", paper.ThemeID); err != nil {
+		return nil, nil, fmt.Errorf("falha ao buscar tema do paper %d: %w", paperID, err)
+	}
+
+	var quiz models.Quiz
+	err = tx.GetContext(ctx, &quiz,
+		`INSERT INTO quizzes (nome, tema_id, quiz_paper_id) VALUES ($1, $2, $3) RETURNING *`,
+		paper.Title, tema.ID, paper.ID,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("falha ao inserir quiz instanciado do paper %d: %w", paperID, err)
+	}
+
+	var sourceQuestionIDs []int
+	err = tx.SelectContext(ctx, &sourceQuestionIDs,
+		"SELECT pergunta_id FROM quiz_paper_questions WHERE quiz_paper_id = $1 ORDER BY posicao ASC", paperID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("falha ao buscar perguntas do paper %d: %w", paperID, err)
+	}
+	if len(sourceQuestionIDs) == 0 {
+		return nil, nil, ErrNoQuestions
+	}
+
+	var perguntasInstanciadas []models.Pergunta
+	for _, sourceID := range sourceQuestionIDs {
+		var sourcePergunta models.Pergunta
+		if err := tx.GetContext(ctx, &sourcePergunta, "SELECT * FROM perguntas WHERE id = $1", sourceID); err != nil {
+			return nil, nil, fmt.Errorf("falha ao buscar pergunta de origem %d: %w", sourceID, err)
+		}
+		// Desencripta o corpo/explicacao da pergunta de origem antes de os
+		// copiar (ver store.QuestionContentProcessor; é um no-op se a
+		// encriptação em repouso não estiver configurada).
+		if err := r.processor.OnLoad(&sourcePergunta); err != nil {
+			return nil, nil, fmt.Errorf("falha ao processar conteúdo da pergunta de origem %d: %w", sourceID, err)
+		}
+
+		var respostas []models.Resposta
+		if err := tx.SelectContext(ctx, &respostas, "SELECT * FROM respostas WHERE pergunta_id = $1", sourceID); err != nil {
+			return nil, nil, fmt.Errorf("falha ao buscar respostas da pergunta de origem %d: %w", sourceID, err)
+		}
+
+		// O corpo/explicacao voltam a passar pelo processor antes do INSERT
+		// na cópia, para a pergunta instanciada ficar gravada protegida tal
+		// como qualquer outra (ver quiz.PostgresRepository.SaveGeneratedQuiz).
+		if err := r.processor.OnSave(&sourcePergunta); err != nil {
+			return nil, nil, fmt.Errorf("falha ao processar conteúdo da pergunta %d para o quiz instanciado: %w", sourceID, err)
+		}
+
+		var pergunta models.Pergunta
+		err = tx.GetContext(ctx, &pergunta,
+			`INSERT INTO perguntas (assunto, corpo, explicacao, quizz_id) VALUES ($1, $2, $3, $4) RETURNING *`,
+			sourcePergunta.Assunto, sourcePergunta.Corpo, sourcePergunta.Explicacao, quiz.ID,
+		)
+		if err != nil {
+			return nil, nil, fmt.Errorf("falha ao copiar pergunta %d para o quiz instanciado: %w", sourceID, err)
+		}
+		// 'pergunta' veio do RETURNING * com o conteúdo ainda encriptado (o
+		// que acabámos de gravar); desencripta antes de devolver ao usecase,
+		// que usa pergunta.Corpo diretamente para montar a resposta da API.
+		if err := r.processor.OnLoad(&pergunta); err != nil {
+			return nil, nil, fmt.Errorf("falha ao processar conteúdo da pergunta instanciada %d: %w", pergunta.ID, err)
+		}
+
+		for _, resposta := range respostas {
+			_, err = tx.ExecContext(ctx,
+				`INSERT INTO respostas (corpo, correta, pergunta_id) VALUES ($1, $2, $3)`,
+				resposta.Corpo, resposta.Correta, pergunta.ID,
+			)
+			if err != nil {
+				return nil, nil, fmt.Errorf("falha ao copiar resposta da pergunta %d: %w", sourceID, err)
+			}
+		}
+
+		perguntasInstanciadas = append(perguntasInstanciadas, pergunta)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("falha ao commitar transação de instanciação de paper: %w", err)
+	}
+
+	return &quiz, perguntasInstanciadas, nil
+}
+
+// linkQuestions insere uma linha em quiz_paper_questions para cada pergunta
+// indicada, na ordem em que aparece em perguntaIDs (índice vira 'posicao').
+func linkQuestions(ctx context.Context, tx *sqlx.Tx, paperID int, perguntaIDs []int) error {
+	for i, perguntaID := range perguntaIDs {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO quiz_paper_questions (quiz_paper_id, pergunta_id, posicao) VALUES ($1, $2, $3)`,
+			paperID, perguntaID, i,
+		)
+		if err != nil {
+			return fmt.Errorf("falha ao ligar pergunta %d ao paper %d: %w", perguntaID, paperID, err)
+		}
+	}
+	return nil
+}
+
+// recomputeQuestionCount recalcula question_count a partir das linhas
+// efetivamente ligadas em quiz_paper_questions (em vez de confiar no
+// tamanho da lista recebida pelo chamador) e grava o resultado em
+// quiz_papers, para o valor nunca divergir do banco de questões mesmo que
+// uma inserção falhe a meio. out recebe o valor recém-gravado.
+func recomputeQuestionCount(ctx context.Context, tx *sqlx.Tx, paperID int, out *int) error {
+	return tx.GetContext(ctx, out,
+		`UPDATE quiz_papers SET question_count = (
+			SELECT COUNT(*) FROM quiz_paper_questions WHERE quiz_paper_id = $1
+		) WHERE id = $1 RETURNING question_count`, paperID)
+}
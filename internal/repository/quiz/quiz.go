@@ -0,0 +1,266 @@
+// Package quiz define o repositório de quizzes (QuizRepository) e a sua
+// implementação Postgres.
+package quiz
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"quizz-core/internal/llm"
+	"quizz-core/internal/logging"
+	"quizz-core/internal/models"
+	"quizz-core/internal/store"
+)
+
+// ErrInvalidSort é devolvido por ListQuizzes quando o parâmetro 'sort' não
+// está na whitelist de colunas ordenáveis; o usecase converte isto num
+// usecase.ErrInvalidInput antes de devolver ao handler.
+var ErrInvalidSort = errors.New("parâmetro de ordenação inválido")
+
+// Repository é a interface que o usecase layer depende, para poder ser
+// trocada por outra implementação (ou um mock) em testes.
+type Repository interface {
+	SaveGeneratedQuiz(ctx context.Context, req models.CreateQuizRequest, llmQuestions []llm.LLMQuestionResponse) (*models.Quiz, []models.Pergunta, error)
+	DeactivateQuiz(ctx context.Context, quizID int) (int64, error)
+	GetActiveQuizzesByTheme(ctx context.Context, themeID int) ([]models.Quiz, error)
+	ListQuizzes(ctx context.Context, filter models.QuizFilter) (models.PagedResponse[models.Quiz], error)
+}
+
+// PostgresRepository implementa Repository contra uma base de dados Postgres.
+type PostgresRepository struct {
+	db        *sqlx.DB
+	processor store.QuestionContentProcessor
+}
+
+// NewPostgresRepository é o construtor da implementação Postgres. processor
+// transforma o conteúdo das perguntas antes de gravar/depois de ler (ver
+// store.QuestionContentProcessor); passe store.NoOpContentProcessor{} quando
+// a encriptação em repouso não estiver configurada.
+func NewPostgresRepository(db *sqlx.DB, processor store.QuestionContentProcessor) *PostgresRepository {
+	return &PostgresRepository{db: db, processor: processor}
+}
+
+// SaveGeneratedQuiz usa uma transação para salvar um quiz completo
+// (Tema, Quiz, Perguntas e Respostas) gerado pela LLM.
+func (r *PostgresRepository) SaveGeneratedQuiz(ctx context.Context, req models.CreateQuizRequest, llmQuestions []llm.LLMQuestionResponse) (*models.Quiz, []models.Pergunta, error) {
+
+	// 1. Iniciar a transação
+	// Usamos Tx para garantir que todas as queries sejam executadas ou nenhuma
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("falha ao iniciar transação: %w", err)
+	}
+	// Garante que, se algo falhar, a transação seja revertida
+	defer tx.Rollback() // Rollback é ignorado se tx.Commit() for chamado
+
+	// --- Lógica do Banco de Dados ---
+
+	// 2. Etapa A: Encontrar ou Criar o Tema
+	// Usamos o 'Theme' (ex: "Biologia Celular") do pedido
+	var tema models.Tema
+	// Tenta buscar o tema pelo nome
+	err = tx.GetContext(ctx, &tema, "SELECT * FROM tema WHERE nome = $1 AND ativo = TRUE", req.Theme)
+
+	if err == sql.ErrNoRows {
+		// Tema não existe, vamos criá-lo
+		logging.FromContext(ctx).Debug("tema não encontrado, criando", "theme", req.Theme)
+		// O 'RETURNING *' nos devolve o objeto 'tema' completo (incluindo o ID e 'criacao')
+		err = tx.GetContext(ctx, &tema,
+			"INSERT INTO tema (nome) VALUES ($1) RETURNING *",
+			req.Theme,
+		)
+		if err != nil {
+			return nil, nil, fmt.Errorf("falha ao inserir novo tema: %w", err)
+		}
+	} else if err != nil {
+		// Outro erro ao buscar o tema
+		return nil, nil, fmt.Errorf("falha ao buscar tema: %w", err)
+	}
+
+	// 3. Etapa B: Criar o Quiz
+	// (Assumimos que sempre criamos um novo quiz)
+	var quiz models.Quiz
+	// O nome do quiz pode ser o próprio tema, ou um nome customizado
+	quizName := req.Theme // Por enquanto, o nome do quiz é o nome do tema
+	err = tx.GetContext(ctx, &quiz,
+		"INSERT INTO quizzes (nome, tema_id) VALUES ($1, $2) RETURNING *",
+		quizName, tema.ID,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("falha ao inserir quiz: %w", err)
+	}
+
+	// 4. Etapa C e D: Criar Perguntas e Respostas (Loop)
+	var perguntasSalvas []models.Pergunta
+
+	for _, llmQ := range llmQuestions {
+		// Etapa C: Inserir a Pergunta. O corpo/explicacao passam pelo
+		// processor antes do INSERT, para quem tiver a encriptação em
+		// repouso configurada gravar sempre o conteúdo protegido.
+		pendingPergunta := models.Pergunta{Assunto: &llmQ.Subject, Corpo: llmQ.QuestionText}
+		if err := r.processor.OnSave(&pendingPergunta); err != nil {
+			return nil, nil, fmt.Errorf("falha ao processar conteúdo da pergunta: %w", err)
+		}
+
+		var pergunta models.Pergunta
+		err = tx.GetContext(ctx, &pergunta,
+			`INSERT INTO perguntas (assunto, corpo, explicacao, quizz_id)
+			 VALUES ($1, $2, $3, $4) RETURNING *`,
+			pendingPergunta.Assunto, pendingPergunta.Corpo, pendingPergunta.Explicacao, quiz.ID,
+		)
+		if err != nil {
+			return nil, nil, fmt.Errorf("falha ao inserir pergunta: %w", err)
+		}
+
+		// Etapa D: Inserir as 4 Respostas
+		for _, opt := range llmQ.Options {
+			isCorrect := (opt == llmQ.CorrectAnswer)
+			_, err = tx.ExecContext(ctx,
+				`INSERT INTO respostas (corpo, correta, pergunta_id)
+				 VALUES ($1, $2, $3)`,
+				opt, isCorrect, pergunta.ID,
+			)
+			if err != nil {
+				return nil, nil, fmt.Errorf("falha ao inserir resposta: %w", err)
+			}
+		}
+		perguntasSalvas = append(perguntasSalvas, pergunta)
+	}
+
+	// 5. Finalizar a Transação
+	// Se chegamos aqui sem erros, 'Commit' salva tudo no banco
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("falha ao commitar transação: %w", err)
+	}
+
+	logging.FromContext(ctx).Info("quiz salvo com sucesso",
+		"quiz_id", quiz.ID, "tema_id", tema.ID, "num_perguntas", len(perguntasSalvas))
+
+	// Retornamos os objetos criados
+	return &quiz, perguntasSalvas, nil
+}
+
+// DeactivateQuiz faz um "soft-delete" de um quiz, definindo ativo = FALSE
+func (r *PostgresRepository) DeactivateQuiz(ctx context.Context, quizID int) (int64, error) {
+
+	query := "UPDATE quizzes SET ativo = FALSE WHERE id = $1 AND ativo = TRUE"
+
+	// Usamos ExecContext para UPDATEs
+	result, err := r.db.ExecContext(ctx, query, quizID)
+	if err != nil {
+		return 0, fmt.Errorf("falha ao executar update para desativar quiz: %w", err)
+	}
+
+	// Verificamos quantas linhas foram realmente alteradas
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("falha ao verificar linhas afetadas: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+// GetActiveQuizzesByTheme mantém a assinatura antiga (sem paginação) para os
+// chamadores que só precisam da lista completa de quizzes ativos de um tema;
+// por baixo, delega em ListQuizzes pedindo o limite máximo.
+func (r *PostgresRepository) GetActiveQuizzesByTheme(ctx context.Context, themeID int) ([]models.Quiz, error) {
+	ativo := true
+	paged, err := r.ListQuizzes(ctx, models.QuizFilter{
+		Pagination: models.Pagination{Page: 1, Limit: maxPageLimitAllQuizzes},
+		ThemeID:    &themeID,
+		Ativo:      &ativo,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return paged.Items, nil
+}
+
+// maxPageLimitAllQuizzes é o limit usado por GetActiveQuizzesByTheme, que
+// precisa da lista inteira e não passa por store.NormalizePagination.
+const maxPageLimitAllQuizzes = 100
+
+// quizSortColumns é a whitelist de colunas ordenáveis de ListQuizzes; impede
+// que o parâmetro 'sort' (vindo da query string) seja usado diretamente no SQL.
+var quizSortColumns = map[string]string{
+	"id":      "id",
+	"nome":    "nome",
+	"criacao": "criacao",
+}
+
+// quizListDBRow acopla o Quiz ao total_count devolvido pela window function
+// COUNT(*) OVER(), para sabermos o total de linhas sem uma segunda query.
+type quizListDBRow struct {
+	models.Quiz
+	TotalCount int `db:"total_count"`
+}
+
+// ListQuizzes lista quizzes com paginação, busca por nome e ordenação,
+// filtrando por tema/ativo/data de criação conforme o filter pedido.
+func (r *PostgresRepository) ListQuizzes(ctx context.Context, filter models.QuizFilter) (models.PagedResponse[models.Quiz], error) {
+	page, limit, offset := store.NormalizePagination(filter.Page, filter.Limit)
+
+	orderBy, err := store.SortClause(filter.Sort, quizSortColumns, "nome ASC")
+	if err != nil {
+		return models.PagedResponse[models.Quiz]{}, fmt.Errorf("%w: %v", ErrInvalidSort, err)
+	}
+
+	conditions := []string{}
+	args := []interface{}{}
+
+	if filter.Ativo != nil {
+		args = append(args, *filter.Ativo)
+		conditions = append(conditions, fmt.Sprintf("ativo = $%d", len(args)))
+	} else {
+		conditions = append(conditions, "ativo = TRUE")
+	}
+	if filter.ThemeID != nil {
+		args = append(args, *filter.ThemeID)
+		conditions = append(conditions, fmt.Sprintf("tema_id = $%d", len(args)))
+	}
+	if filter.NomeContains != "" {
+		args = append(args, "%"+filter.NomeContains+"%")
+		conditions = append(conditions, fmt.Sprintf("nome ILIKE $%d", len(args)))
+	}
+	if filter.CreatedAfter != nil {
+		args = append(args, *filter.CreatedAfter)
+		conditions = append(conditions, fmt.Sprintf("criacao > $%d", len(args)))
+	}
+
+	whereClause := strings.Join(conditions, " AND ")
+
+	args = append(args, limit, offset)
+	query := fmt.Sprintf(
+		`SELECT *, COUNT(*) OVER() AS total_count FROM quizzes WHERE %s ORDER BY %s LIMIT $%d OFFSET $%d`,
+		whereClause, orderBy, len(args)-1, len(args),
+	)
+
+	var rows []quizListDBRow
+	if err := r.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return models.PagedResponse[models.Quiz]{}, fmt.Errorf("falha ao listar quizzes: %w", err)
+	}
+
+	total := 0
+	items := make([]models.Quiz, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, row.Quiz)
+		total = row.TotalCount
+	}
+
+	totalPages := (total + limit - 1) / limit
+	if total == 0 {
+		totalPages = 0
+	}
+
+	return models.PagedResponse[models.Quiz]{
+		Items:      items,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: totalPages,
+	}, nil
+}
@@ -0,0 +1,665 @@
+// Package submission define o repositório de submissões (SubmissionRepository)
+// e a sua implementação Postgres. Agrupa tanto a gravação/leitura de
+// submissões individuais como as estatísticas agregadas por utilizador,
+// porque ambas giram em torno das mesmas tabelas (submissao, respostas_dadas).
+package submission
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"quizz-core/internal/logging"
+	"quizz-core/internal/models"
+	"quizz-core/internal/store"
+)
+
+// Repository é a interface que o usecase layer depende, para poder ser
+// trocada por outra implementação (ou um mock) em testes.
+type Repository interface {
+	GetQuizAnswers(ctx context.Context, quizID int) (map[string]QuestionAnswerInfo, error)
+	SaveSubmissionStats(ctx context.Context, sub models.Submissao, dadas []models.RespostaDada, difs []models.Dificuldade, idempotency *models.IdempotencyInfo) (*models.Submissao, error)
+	GetSubmissionDetails(ctx context.Context, submissionID int) (*models.SubmissionDetailResponse, error)
+	GetUserStats(ctx context.Context, userID int) (*models.UserStatsResponse, error)
+	GetIdempotencyRecord(ctx context.Context, userID int, key string) (*models.IdempotencyRecord, error)
+	GetSubmissionSummary(ctx context.Context, submissionID int) (*models.SubmissionResponse, error)
+	GetSubmissionOwner(ctx context.Context, submissionID int) (int, error)
+	SaveDraftAnswers(ctx context.Context, userID, quizID int, answers map[int]int) error
+	GetDraftAnswers(ctx context.Context, userID, quizID int) (map[int]int, error)
+	ListUserSubmissions(ctx context.Context, userID int, pagination models.Pagination) (models.PagedResponse[models.UserSubmissionHistoryResponse], error)
+}
+
+// PostgresRepository implementa Repository contra uma base de dados Postgres.
+type PostgresRepository struct {
+	db        *sqlx.DB
+	processor store.QuestionContentProcessor
+}
+
+// NewPostgresRepository é o construtor da implementação Postgres. processor
+// desencripta o corpo das perguntas lido em GetSubmissionDetails (ver
+// store.QuestionContentProcessor); passe store.NoOpContentProcessor{} quando
+// a encriptação em repouso não estiver configurada.
+func NewPostgresRepository(db *sqlx.DB, processor store.QuestionContentProcessor) *PostgresRepository {
+	return &PostgresRepository{db: db, processor: processor}
+}
+
+// QuestionAnswerInfo é uma struct simples para guardar o gabarito
+type QuestionAnswerInfo struct {
+	QuestionID        int
+	Assunto           string
+	CorrectOptionText string         // O texto da opção correta
+	CorrectOptionID   int            // O ID da opção correta
+	OptionsMap        map[string]int // Mapa de [Texto da Opção] -> [ID da Opção]
+}
+
+// dbAnswerRow é uma struct interna para ler todas as opcoes
+type dbAnswerRow struct {
+	QuestionID      int     `db:"pergunta_id"`
+	QuestionAssunto *string `db:"assunto"`
+	RespostaID      int     `db:"resposta_id"`
+	CorpoResposta   string  `db:"corpo_resposta"`
+	Correta         bool    `db:"correta"`
+}
+
+// GetQuizAnswers busca o gabarito (respostas corretas) para um quiz
+func (r *PostgresRepository) GetQuizAnswers(ctx context.Context, quizID int) (map[string]QuestionAnswerInfo, error) {
+	// Esta query busca TODAS as respostas de TODAS as perguntas de um quiz
+	query := `
+		SELECT
+			p.id AS pergunta_id,
+			p.assunto,
+			r.id AS resposta_id,
+			r.corpo AS corpo_resposta,
+			r.correta
+		FROM
+			perguntas p
+		JOIN
+			respostas r ON p.id = r.pergunta_id
+		WHERE
+			p.quizz_id = $1
+	`
+
+	var allAnswers []dbAnswerRow
+	if err := r.db.SelectContext(ctx, &allAnswers, query, quizID); err != nil {
+		return nil, fmt.Errorf("falha ao buscar gabarito completo: %w", err)
+	}
+
+	// Agora, processamos o resultado (que está "achatado") num mapa complexo
+	answerMap := make(map[string]QuestionAnswerInfo)
+
+	for _, row := range allAnswers {
+		qIDStr := strconv.Itoa(row.QuestionID)
+
+		// Verifica se já começámos a processar esta pergunta
+		info, exists := answerMap[qIDStr]
+		if !exists {
+			// Primeira vez que vemos esta pergunta
+			info = QuestionAnswerInfo{
+				QuestionID: row.QuestionID,
+				Assunto:    "", // Default
+				OptionsMap: make(map[string]int),
+			}
+			if row.QuestionAssunto != nil {
+				info.Assunto = *row.QuestionAssunto
+			}
+		}
+
+		// Adiciona a opção ao mapa de opções
+		info.OptionsMap[row.CorpoResposta] = row.RespostaID
+
+		// Se esta for a resposta correta, guarda o texto e o ID
+		if row.Correta {
+			info.CorrectOptionText = row.CorpoResposta
+			info.CorrectOptionID = row.RespostaID
+		}
+
+		// Coloca de volta no mapa
+		answerMap[qIDStr] = info
+	}
+
+	return answerMap, nil
+}
+
+// SaveSubmissionStats é uma função transacional para salvar os resultados completos
+func (r *PostgresRepository) SaveSubmissionStats(ctx context.Context, sub models.Submissao, dadas []models.RespostaDada, difs []models.Dificuldade, idempotency *models.IdempotencyInfo) (*models.Submissao, error) {
+
+	// 1. Iniciar a transação
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao iniciar transação de submissão: %w", err)
+	}
+	defer tx.Rollback()
+
+	// 2. Etapa A: Salvar a Submissão principal (tabela 'submissao')
+	var savedSub models.Submissao
+	err = tx.GetContext(ctx, &savedSub,
+		`INSERT INTO submissao (datahora, pontuacao, utilizador_id, quizz_id)
+		 VALUES ($1, $2, $3, $4) RETURNING *`,
+		sub.DataHora, sub.Pontuacao, sub.UtilizadorID, sub.QuizzID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao inserir submissao: %w", err)
+	}
+
+	// Usamos o ID da submissão que acabamos de criar
+	submissionID := savedSub.ID
+
+	// 3. Etapa B: Salvar as Respostas Dadas (tabela 'respostas_dadas')
+	for _, dada := range dadas {
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO respostas_dadas (submissao_id, pergunta_id, resposta_id, correta_na_submissao)
+		 VALUES ($1, $2, $3, $4)`,
+			submissionID, dada.PerguntaID, dada.RespostaID, dada.CorretaNaSubmissao, // <-- O 'dada.RespostaID' é a correção
+		)
+		if err != nil {
+			return nil, fmt.Errorf("falha ao inserir resposta_dada: %w", err)
+		}
+	}
+
+	// 4. Etapa C: Salvar as Dificuldades (tabela 'dificuldades')
+	for _, dif := range difs {
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO dificuldades (assunto, submissao_id)
+			 VALUES ($1, $2)`,
+			dif.Assunto, submissionID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("falha ao inserir dificuldade: %w", err)
+		}
+	}
+
+	// 4.1 Etapa D: promover o draft a submissão final, apagando-o da mesma
+	// transação (se o utilizador nunca gravou um draft para este quiz, isto
+	// simplesmente não apaga nenhuma linha)
+	if _, err = tx.ExecContext(ctx,
+		"DELETE FROM respostas_dadas_draft WHERE utilizador_id = $1 AND quizz_id = $2",
+		sub.UtilizadorID, sub.QuizzID,
+	); err != nil {
+		return nil, fmt.Errorf("falha ao limpar draft da submissão: %w", err)
+	}
+
+	// 4.2 Etapa E: gravar o registo de idempotência, se o cliente enviou
+	// o header 'Idempotency-Key'
+	if idempotency != nil {
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO submission_idempotency (idempotency_key, utilizador_id, quizz_id, submissao_id, request_hash)
+			 VALUES ($1, $2, $3, $4, $5)`,
+			idempotency.Key, sub.UtilizadorID, sub.QuizzID, submissionID, idempotency.RequestHash,
+		)
+		if err != nil {
+			var pqErr *pq.Error
+			if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+				// Outro pedido concorrente com a mesma Idempotency-Key já
+				// gravou o seu registo primeiro; a transação inteira é
+				// revertida (defer tx.Rollback()) e o usecase busca a
+				// resposta da vencedora em vez de reprocessar.
+				return nil, ErrIdempotencyConflict
+			}
+			return nil, fmt.Errorf("falha ao gravar registo de idempotência: %w", err)
+		}
+	}
+
+	// 5. Finalizar a Transação
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("falha ao commitar transação de submissão: %w", err)
+	}
+
+	logging.FromContext(ctx).Info("submissão salva com sucesso",
+		"submission_id", savedSub.ID, "num_respostas", len(dadas), "num_dificuldades", len(difs))
+
+	return &savedSub, nil
+}
+
+// GetIdempotencyRecord busca um registo de idempotência previamente gravado
+// para este utilizador e esta chave, para detectar um retry de uma
+// submissão já processada. sql.ErrNoRows é devolvido sem wrap quando não
+// existe nenhum registo, para o usecase distinguir "nunca visto" de erro.
+func (r *PostgresRepository) GetIdempotencyRecord(ctx context.Context, userID int, key string) (*models.IdempotencyRecord, error) {
+	var rec models.IdempotencyRecord
+	err := r.db.GetContext(ctx, &rec,
+		"SELECT * FROM submission_idempotency WHERE utilizador_id = $1 AND idempotency_key = $2",
+		userID, key,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("falha ao buscar registo de idempotência: %w", err)
+	}
+	return &rec, nil
+}
+
+// GetSubmissionOwner devolve o utilizador_id dono de uma submissão, para o
+// usecase confirmar a posse antes de listar/criar comentários de QA sobre
+// ela (ver ListSubmissionComments/CreateSubmissionComment). sql.ErrNoRows é
+// devolvido sem wrap quando a submissão não existe.
+func (r *PostgresRepository) GetSubmissionOwner(ctx context.Context, submissionID int) (int, error) {
+	var userID int
+	err := r.db.GetContext(ctx, &userID, "SELECT utilizador_id FROM submissao WHERE id = $1", submissionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, sql.ErrNoRows
+		}
+		return 0, fmt.Errorf("falha ao buscar dono da submissão %d: %w", submissionID, err)
+	}
+	return userID, nil
+}
+
+// submissionSummaryDBRow é uma struct interna para recalcular a resposta de
+// uma submissão já existente, usada ao reproduzir um retry idempotente.
+type submissionSummaryDBRow struct {
+	SubmissionID int           `db:"id"`
+	Pontuacao    float64       `db:"pontuacao"`
+	Total        sql.NullInt64 `db:"total"`
+	Corretas     sql.NullInt64 `db:"corretas"`
+}
+
+// GetSubmissionSummary reconstrói o SubmissionResponse de uma submissão já
+// gravada, contando as respostas dadas associadas a ela. Usado para devolver
+// a mesma resposta original quando um pedido com Idempotency-Key é repetido.
+func (r *PostgresRepository) GetSubmissionSummary(ctx context.Context, submissionID int) (*models.SubmissionResponse, error) {
+	query := `
+		SELECT
+			s.id,
+			s.pontuacao,
+			COUNT(rd.id) AS total,
+			SUM(CASE WHEN rd.correta_na_submissao THEN 1 ELSE 0 END) AS corretas
+		FROM submissao s
+		LEFT JOIN respostas_dadas rd ON rd.submissao_id = s.id
+		WHERE s.id = $1
+		GROUP BY s.id, s.pontuacao
+	`
+	var row submissionSummaryDBRow
+	if err := r.db.GetContext(ctx, &row, query, submissionID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("falha ao recalcular resumo da submissão %d: %w", submissionID, err)
+	}
+
+	total := int(row.Total.Int64)
+	corretas := int(row.Corretas.Int64)
+	return &models.SubmissionResponse{
+		SubmissionID: row.SubmissionID,
+		Score:        row.Pontuacao,
+		CorrectCount: corretas,
+		TotalCount:   total,
+		Message:      fmt.Sprintf("Submissão bem-sucedida! Acertou %d de %d.", corretas, total),
+	}, nil
+}
+
+// SaveDraftAnswers grava (upsert) as respostas parciais de um utilizador
+// para um quiz, uma linha por pergunta já respondida no draft.
+func (r *PostgresRepository) SaveDraftAnswers(ctx context.Context, userID, quizID int, answers map[int]int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("falha ao iniciar transação de draft: %w", err)
+	}
+	defer tx.Rollback()
+
+	for perguntaID, respostaID := range answers {
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO respostas_dadas_draft (utilizador_id, quizz_id, pergunta_id, resposta_id, atualizado_em)
+			 VALUES ($1, $2, $3, $4, NOW())
+			 ON CONFLICT (utilizador_id, quizz_id, pergunta_id)
+			 DO UPDATE SET resposta_id = EXCLUDED.resposta_id, atualizado_em = NOW()`,
+			userID, quizID, perguntaID, respostaID,
+		)
+		if err != nil {
+			return fmt.Errorf("falha ao gravar resposta de draft: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("falha ao commitar transação de draft: %w", err)
+	}
+	return nil
+}
+
+// GetDraftAnswers busca as respostas parciais já gravadas para um
+// utilizador e um quiz, como um mapa de pergunta_id -> resposta_id.
+func (r *PostgresRepository) GetDraftAnswers(ctx context.Context, userID, quizID int) (map[int]int, error) {
+	type draftRow struct {
+		PerguntaID int `db:"pergunta_id"`
+		RespostaID int `db:"resposta_id"`
+	}
+	var rows []draftRow
+	err := r.db.SelectContext(ctx, &rows,
+		"SELECT pergunta_id, resposta_id FROM respostas_dadas_draft WHERE utilizador_id = $1 AND quizz_id = $2",
+		userID, quizID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao buscar respostas de draft: %w", err)
+	}
+
+	answers := make(map[int]int, len(rows))
+	for _, row := range rows {
+		answers[row.PerguntaID] = row.RespostaID
+	}
+	return answers, nil
+}
+
+type submissionDetailDBRow struct {
+	// Info da Submissão e Quiz (repetido em cada linha)
+	SubmissionID int       `db:"submissao_id"`
+	QuizID       int       `db:"quizz_id"`
+	QuizNome     string    `db:"quiz_nome"`
+	TemaNome     string    `db:"tema_nome"`
+	Pontuacao    float64   `db:"pontuacao"`
+	DataHora     time.Time `db:"datahora"`
+
+	// Info da Pergunta
+	PerguntaID    int     `db:"pergunta_id"`
+	CorpoPergunta string  `db:"corpo_pergunta"`
+	Assunto       *string `db:"assunto"`
+
+	// Info da Resposta (Opção)
+	RespostaID    int    `db:"resposta_id"`
+	CorpoResposta string `db:"corpo_resposta"`
+	Correta       bool   `db:"correta"` // Se *esta opção* é a correta
+
+	// Info da Resposta Dada pelo Utilizador
+	// Usamos sql.NullInt64 e sql.NullBool porque pode não haver resposta dada para uma pergunta
+	RespostaDadaID     sql.NullInt64 `db:"resposta_dada_id"`     // O ID da resposta que o user escolheu
+	CorretaNaSubmissao sql.NullBool  `db:"correta_na_submissao"` // Se o user acertou esta pergunta
+}
+
+// GetSubmissionDetails busca todos os detalhes de uma submissão específica
+func (r *PostgresRepository) GetSubmissionDetails(ctx context.Context, submissionID int) (*models.SubmissionDetailResponse, error) {
+
+	// Query complexa que junta 5 tabelas!
+	query := `
+		SELECT
+			s.id AS submissao_id,
+			s.quizz_id,
+			q.nome AS quiz_nome,
+			t.nome AS tema_nome,
+			s.pontuacao,
+			s.datahora,
+			p.id AS pergunta_id,
+			p.corpo AS corpo_pergunta,
+			p.assunto,
+			r.id AS resposta_id,
+			r.corpo AS corpo_resposta,
+			r.correta,
+			rd.resposta_id AS resposta_dada_id,
+			rd.correta_na_submissao
+		FROM
+			submissao s
+		JOIN
+			quizzes q ON s.quizz_id = q.id
+		JOIN
+			tema t ON q.tema_id = t.id
+		JOIN
+			perguntas p ON q.id = p.quizz_id
+		JOIN
+			respostas r ON p.id = r.pergunta_id
+		LEFT JOIN -- LEFT JOIN porque pode não haver uma resposta dada
+			respostas_dadas rd ON s.id = rd.submissao_id AND p.id = rd.pergunta_id
+		WHERE
+			s.id = $1
+		ORDER BY
+			p.id, r.id -- Importante ordenar para agrupar corretamente
+	`
+
+	var resultsDB []submissionDetailDBRow
+	if err := r.db.SelectContext(ctx, &resultsDB, query, submissionID); err != nil {
+		if err == sql.ErrNoRows {
+			// Submissão não encontrada
+			return nil, sql.ErrNoRows // Retornamos o erro original para o usecase tratar como 404
+		}
+		return nil, fmt.Errorf("falha ao buscar detalhes da submissão %d: %w", submissionID, err)
+	}
+
+	// Se não houver resultados, significa que a submissão não existe
+	if len(resultsDB) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	// --- Processar os Resultados (Agrupar por Pergunta) ---
+	// A query retorna uma linha para CADA OPÇÃO de CADA PERGUNTA.
+	// Precisamos de agrupar isto na estrutura da nossa API.
+
+	// Pegamos a informação geral da primeira linha (é repetida)
+	firstRow := resultsDB[0]
+	responseAPI := &models.SubmissionDetailResponse{
+		SubmissionID: firstRow.SubmissionID,
+		QuizID:       firstRow.QuizID,
+		QuizNome:     firstRow.QuizNome,
+		TemaNome:     firstRow.TemaNome,
+		Pontuacao:    firstRow.Pontuacao,
+		DataHora:     firstRow.DataHora,
+		Perguntas:    []models.QuestionDetailResponse{}, // Inicializa o array vazio
+	}
+
+	// Usamos um mapa para agrupar as opções por pergunta_id
+	perguntasMap := make(map[int]*models.QuestionDetailResponse)
+
+	for _, row := range resultsDB {
+		perguntaID := row.PerguntaID
+
+		// Verifica se já começámos a processar esta pergunta
+		detalhePergunta, exists := perguntasMap[perguntaID]
+		if !exists {
+			// Desencripta o corpo da pergunta antes de expor na resposta
+			// (ver store.QuestionContentProcessor; é um no-op se a
+			// encriptação em repouso não estiver configurada).
+			pergunta := models.Pergunta{ID: perguntaID, Corpo: row.CorpoPergunta}
+			if err := r.processor.OnLoad(&pergunta); err != nil {
+				return nil, fmt.Errorf("falha ao processar conteúdo da pergunta %d: %w", perguntaID, err)
+			}
+
+			// Primeira vez que vemos esta pergunta, criamos a struct base
+			detalhePergunta = &models.QuestionDetailResponse{
+				PerguntaID:    perguntaID,
+				CorpoPergunta: pergunta.Corpo,
+				Assunto:       row.Assunto,
+				Opcoes:        []models.AnswerOptionDetail{},
+				Acertou:       row.CorretaNaSubmissao.Bool, // Pega o valor (pode ser false se NullBool)
+			}
+			perguntasMap[perguntaID] = detalhePergunta
+		}
+
+		// Adiciona a opção atual à lista de opções da pergunta
+		detalhePergunta.Opcoes = append(detalhePergunta.Opcoes, models.AnswerOptionDetail{
+			RespostaID: row.RespostaID,
+			Corpo:      row.CorpoResposta,
+		})
+
+		// Se *esta opção* for a correta, guardamos o texto dela
+		if row.Correta {
+			detalhePergunta.RespostaCorreta = row.CorpoResposta
+		}
+
+		// Se *esta opção* foi a que o utilizador escolheu, guardamos o texto dela
+		// Comparamos o ID desta opção (row.RespostaID) com o ID que o user escolheu (row.RespostaDadaID)
+		if row.RespostaDadaID.Valid && row.RespostaDadaID.Int64 == int64(row.RespostaID) {
+			respostaUser := row.CorpoResposta // Guarda o texto da opção escolhida
+			detalhePergunta.RespostaUtilizador = &respostaUser
+		}
+	}
+
+	// Adiciona as perguntas agrupadas (do mapa) à resposta final
+	for _, p := range perguntasMap {
+		responseAPI.Perguntas = append(responseAPI.Perguntas, *p)
+	}
+
+	return responseAPI, nil
+}
+
+// statsDBRow é uma struct interna para ler os resultados agregados do SQL
+type statsDBRow struct {
+	TotalQuizzesRealizados    sql.NullInt64   `db:"total_quizzes"`
+	PontuacaoMedia            sql.NullFloat64 `db:"avg_score"`
+	TotalPerguntasRespondidas sql.NullInt64   `db:"total_respostas"`
+	TotalAcertos              sql.NullInt64   `db:"total_acertos"`
+}
+
+// GetUserStats calcula as estatísticas agregadas para um utilizador
+func (r *PostgresRepository) GetUserStats(ctx context.Context, userID int) (*models.UserStatsResponse, error) {
+
+	// Esta query junta 'submissao' e 'respostas_dadas' para calcular tudo de uma vez
+	query := `
+		SELECT
+			COUNT(DISTINCT s.id) AS total_quizzes,
+			AVG(s.pontuacao) AS avg_score,
+			COUNT(rd.id) AS total_respostas,
+			SUM(CASE WHEN rd.correta_na_submissao = TRUE THEN 1 ELSE 0 END) AS total_acertos
+		FROM
+			submissao s
+		LEFT JOIN
+			respostas_dadas rd ON s.id = rd.submissao_id
+		WHERE
+			s.utilizador_id = $1
+	`
+
+	var stats statsDBRow
+	if err := r.db.GetContext(ctx, &stats, query, userID); err != nil {
+		if err == sql.ErrNoRows {
+			// Se não houver linhas, significa que o utilizador existe mas nunca fez um quiz
+			// Retornamos estatísticas "zero" em vez de um erro
+			return &models.UserStatsResponse{
+				UserID: strconv.Itoa(userID),
+			}, nil
+		}
+		return nil, fmt.Errorf("falha ao buscar estatísticas do utilizador %d: %w", userID, err)
+	}
+
+	// Converter os tipos do DB (que podem ser nulos) para os tipos do nosso modelo
+
+	totalErros := stats.TotalPerguntasRespondidas.Int64 - stats.TotalAcertos.Int64
+	var percentagemAcerto float64 = 0
+	if stats.TotalPerguntasRespondidas.Int64 > 0 {
+		percentagemAcerto = (float64(stats.TotalAcertos.Int64) / float64(stats.TotalPerguntasRespondidas.Int64)) * 100.0
+	}
+
+	response := &models.UserStatsResponse{
+		UserID:                    strconv.Itoa(userID),
+		TotalQuizzesRealizados:    int(stats.TotalQuizzesRealizados.Int64),
+		PontuacaoMedia:            stats.PontuacaoMedia.Float64,
+		TotalPerguntasRespondidas: int(stats.TotalPerguntasRespondidas.Int64),
+		TotalAcertos:              int(stats.TotalAcertos.Int64),
+		TotalErros:                int(totalErros),
+		PercentagemAcerto:         percentagemAcerto,
+	}
+
+	return response, nil
+}
+
+// historyDBRow é a struct interna para ler os dados do JOIN
+type historyDBRow struct {
+	SubmissionID int       `db:"id"`
+	QuizID       int       `db:"quizz_id"`
+	QuizNome     string    `db:"quiz_nome"`
+	TemaNome     string    `db:"tema_nome"`
+	Pontuacao    float64   `db:"pontuacao"`
+	DataHora     time.Time `db:"datahora"`
+}
+
+// submissionSortColumns é a whitelist de colunas ordenáveis de
+// ListUserSubmissions; impede que o parâmetro 'sort' (vindo da query string)
+// seja usado diretamente no SQL.
+var submissionSortColumns = map[string]string{
+	"datahora":  "s.datahora",
+	"pontuacao": "s.pontuacao",
+}
+
+// ErrInvalidSort é devolvido por ListUserSubmissions quando o parâmetro
+// 'sort' não está na whitelist de colunas ordenáveis; o usecase converte
+// isto num usecase.ErrInvalidInput antes de devolver ao handler.
+var ErrInvalidSort = errors.New("parâmetro de ordenação inválido")
+
+// ErrIdempotencyConflict é devolvido por SaveSubmissionStats quando duas
+// submissões concorrentes com a mesma Idempotency-Key perdem a corrida contra
+// a constraint UNIQUE (utilizador_id, idempotency_key) de submission_idempotency:
+// a que chega primeiro grava normalmente, a outra recebe este erro. O usecase
+// trata-o buscando o registo já gravado pela vencedora (GetIdempotencyRecord)
+// e devolvendo a mesma resposta (GetSubmissionSummary), em vez de um 500.
+var ErrIdempotencyConflict = errors.New("registo de idempotência já foi gravado por um pedido concorrente")
+
+// historyListDBRow acopla historyDBRow ao total_count devolvido pela window
+// function COUNT(*) OVER(), para sabermos o total de linhas sem uma segunda query.
+type historyListDBRow struct {
+	historyDBRow
+	TotalCount int `db:"total_count"`
+}
+
+// ListUserSubmissions lista o histórico de submissões de um utilizador com
+// paginação, busca por nome do quiz/tema e ordenação.
+func (r *PostgresRepository) ListUserSubmissions(ctx context.Context, userID int, pagination models.Pagination) (models.PagedResponse[models.UserSubmissionHistoryResponse], error) {
+	page, limit, offset := store.NormalizePagination(pagination.Page, pagination.Limit)
+
+	orderBy, err := store.SortClause(pagination.Sort, submissionSortColumns, "s.datahora DESC")
+	if err != nil {
+		return models.PagedResponse[models.UserSubmissionHistoryResponse]{}, fmt.Errorf("%w: %v", ErrInvalidSort, err)
+	}
+
+	conditions := []string{"s.utilizador_id = $1"}
+	args := []interface{}{userID}
+
+	if pagination.Q != "" {
+		args = append(args, "%"+pagination.Q+"%")
+		conditions = append(conditions, fmt.Sprintf("(q.nome ILIKE $%d OR t.nome ILIKE $%d)", len(args), len(args)))
+	}
+
+	whereClause := strings.Join(conditions, " AND ")
+
+	args = append(args, limit, offset)
+	query := fmt.Sprintf(`
+		SELECT
+			s.id,
+			s.quizz_id,
+			q.nome AS quiz_nome,
+			t.nome AS tema_nome,
+			s.pontuacao,
+			s.datahora,
+			COUNT(*) OVER() AS total_count
+		FROM
+			submissao s
+		JOIN
+			quizzes q ON s.quizz_id = q.id
+		JOIN
+			tema t ON q.tema_id = t.id
+		WHERE %s
+		ORDER BY %s
+		LIMIT $%d OFFSET $%d
+	`, whereClause, orderBy, len(args)-1, len(args))
+
+	var rows []historyListDBRow
+	if err := r.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return models.PagedResponse[models.UserSubmissionHistoryResponse]{}, fmt.Errorf("falha ao listar histórico de submissões: %w", err)
+	}
+
+	total := 0
+	items := make([]models.UserSubmissionHistoryResponse, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, models.UserSubmissionHistoryResponse{
+			SubmissionID: row.SubmissionID,
+			QuizID:       row.QuizID,
+			QuizNome:     row.QuizNome,
+			TemaNome:     row.TemaNome,
+			Pontuacao:    row.Pontuacao,
+			DataHora:     row.DataHora,
+		})
+		total = row.TotalCount
+	}
+
+	totalPages := (total + limit - 1) / limit
+	if total == 0 {
+		totalPages = 0
+	}
+
+	return models.PagedResponse[models.UserSubmissionHistoryResponse]{
+		Items:      items,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: totalPages,
+	}, nil
+}
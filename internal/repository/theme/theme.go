@@ -0,0 +1,39 @@
+// Package theme define o repositório de temas (ThemeRepository) e a sua
+// implementação Postgres.
+package theme
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"quizz-core/internal/models"
+)
+
+// Repository é a interface que o usecase layer depende, para poder ser
+// trocada por outra implementação (ou um mock) em testes.
+type Repository interface {
+	GetAllActiveThemes(ctx context.Context) ([]models.Tema, error)
+}
+
+// PostgresRepository implementa Repository contra uma base de dados Postgres.
+type PostgresRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresRepository é o construtor da implementação Postgres
+func NewPostgresRepository(db *sqlx.DB) *PostgresRepository {
+	return &PostgresRepository{db: db}
+}
+
+// GetAllActiveThemes busca todos os temas que estão marcados como 'ativo = TRUE'
+func (r *PostgresRepository) GetAllActiveThemes(ctx context.Context) ([]models.Tema, error) {
+	query := "SELECT * FROM tema WHERE ativo = TRUE ORDER BY nome ASC"
+
+	temas := []models.Tema{}
+	if err := r.db.SelectContext(ctx, &temas, query); err != nil {
+		return nil, fmt.Errorf("falha ao buscar temas ativos: %w", err)
+	}
+
+	return temas, nil
+}
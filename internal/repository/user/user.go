@@ -0,0 +1,64 @@
+// Package user define o repositório de utilizadores (UserRepository) e a
+// sua implementação Postgres.
+package user
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"quizz-core/internal/models"
+)
+
+// Repository é a interface que o subsistema de auth depende, para poder ser
+// trocada por outra implementação (ou um mock) em testes.
+type Repository interface {
+	CreateUtilizador(ctx context.Context, nome, passwordHash, tipo string) (*models.Utilizador, error)
+	GetUtilizadorByNome(ctx context.Context, nome string) (*models.Utilizador, error)
+}
+
+// PostgresRepository implementa Repository contra uma base de dados Postgres.
+type PostgresRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresRepository é o construtor da implementação Postgres
+func NewPostgresRepository(db *sqlx.DB) *PostgresRepository {
+	return &PostgresRepository{db: db}
+}
+
+// CreateUtilizador insere um novo utilizador em 'utilizadores' com a
+// password já em hash. 'tipo' vazio cai no default da tabela ("aluno").
+func (r *PostgresRepository) CreateUtilizador(ctx context.Context, nome, passwordHash, tipo string) (*models.Utilizador, error) {
+	var user models.Utilizador
+	var err error
+	if tipo == "" {
+		err = r.db.GetContext(ctx, &user,
+			`INSERT INTO utilizadores (nome, password) VALUES ($1, $2) RETURNING *`,
+			nome, passwordHash,
+		)
+	} else {
+		err = r.db.GetContext(ctx, &user,
+			`INSERT INTO utilizadores (nome, password, tipo) VALUES ($1, $2, $3) RETURNING *`,
+			nome, passwordHash, tipo,
+		)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("falha ao inserir utilizador: %w", err)
+	}
+	return &user, nil
+}
+
+// GetUtilizadorByNome busca um utilizador pelo nome, para validar o login
+func (r *PostgresRepository) GetUtilizadorByNome(ctx context.Context, nome string) (*models.Utilizador, error) {
+	var user models.Utilizador
+	err := r.db.GetContext(ctx, &user, "SELECT * FROM utilizadores WHERE nome = $1", nome)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("falha ao buscar utilizador por nome: %w", err)
+	}
+	return &user, nil
+}
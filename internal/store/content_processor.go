@@ -0,0 +1,210 @@
+package store
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"quizz-core/internal/models"
+)
+
+// QuestionContentProcessor dá aos repositórios um ponto único para
+// transformar o conteúdo de uma pergunta antes de gravar e depois de ler,
+// sem que eles precisem saber se (ou como) esse conteúdo está protegido em
+// repouso. SaveGeneratedQuiz e InstantiatePaper chamam OnSave antes do
+// INSERT; todo o caminho de leitura (GetQuizAnswers, GetSubmissionDetails,
+// InstantiatePaper, etc.) chama OnLoad depois de ler as linhas do banco.
+type QuestionContentProcessor interface {
+	OnSave(p *models.Pergunta) error
+	OnLoad(p *models.Pergunta) error
+}
+
+// NoOpContentProcessor não transforma nada: é o processor usado quando a
+// encriptação em repouso não está configurada, preservando o comportamento
+// anterior.
+type NoOpContentProcessor struct{}
+
+func (NoOpContentProcessor) OnSave(*models.Pergunta) error { return nil }
+func (NoOpContentProcessor) OnLoad(*models.Pergunta) error { return nil }
+
+// AesGcmProcessor encripta 'corpo' e 'explicacao' com AES-256-GCM antes de
+// gravar, e desencripta depois de ler, guardando o resultado nas mesmas
+// colunas (como hex) para não exigir nenhuma migração de schema nem mudança
+// na API. O valor guardado é [key_id(1 byte)][nonce(12 bytes)][ciphertext+tag],
+// o que permite ter várias chaves configuradas ao mesmo tempo: novas
+// gravações usam sempre activeKeyID, mas a leitura escolhe a chave certa
+// pelo key_id embutido, para dados antigos continuarem legíveis depois de
+// uma rotação de chave.
+type AesGcmProcessor struct {
+	keys        map[byte][]byte
+	activeKeyID byte
+}
+
+// NewAesGcmProcessor recebe o conjunto de chaves disponíveis (key-id -> 32
+// bytes, AES-256) e qual delas deve ser usada para novas gravações.
+func NewAesGcmProcessor(keys map[byte][]byte, activeKeyID byte) (*AesGcmProcessor, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("nenhuma chave de encriptação fornecida")
+	}
+	if _, ok := keys[activeKeyID]; !ok {
+		return nil, fmt.Errorf("chave ativa (key-id %d) não está presente no conjunto de chaves fornecido", activeKeyID)
+	}
+	for id, key := range keys {
+		if len(key) != 32 {
+			return nil, fmt.Errorf("chave %d tem %d bytes, esperado 32 (AES-256)", id, len(key))
+		}
+	}
+	return &AesGcmProcessor{keys: keys, activeKeyID: activeKeyID}, nil
+}
+
+// OnSave encripta corpo (sempre) e explicacao (se preenchida) in-place.
+func (p *AesGcmProcessor) OnSave(pergunta *models.Pergunta) error {
+	encryptedCorpo, err := p.encrypt(pergunta.Corpo)
+	if err != nil {
+		return fmt.Errorf("falha ao encriptar corpo da pergunta: %w", err)
+	}
+	pergunta.Corpo = encryptedCorpo
+
+	if pergunta.Explicacao != nil {
+		encryptedExplicacao, err := p.encrypt(*pergunta.Explicacao)
+		if err != nil {
+			return fmt.Errorf("falha ao encriptar explicacao da pergunta: %w", err)
+		}
+		pergunta.Explicacao = &encryptedExplicacao
+	}
+	return nil
+}
+
+// OnLoad desencripta corpo e explicacao in-place, usando a chave identificada
+// pelo key-id embutido no valor guardado (não necessariamente activeKeyID).
+func (p *AesGcmProcessor) OnLoad(pergunta *models.Pergunta) error {
+	decryptedCorpo, err := p.decrypt(pergunta.Corpo)
+	if err != nil {
+		return fmt.Errorf("falha ao desencriptar corpo da pergunta %d: %w", pergunta.ID, err)
+	}
+	pergunta.Corpo = decryptedCorpo
+
+	if pergunta.Explicacao != nil {
+		decryptedExplicacao, err := p.decrypt(*pergunta.Explicacao)
+		if err != nil {
+			return fmt.Errorf("falha ao desencriptar explicacao da pergunta %d: %w", pergunta.ID, err)
+		}
+		pergunta.Explicacao = &decryptedExplicacao
+	}
+	return nil
+}
+
+func (p *AesGcmProcessor) encrypt(plaintext string) (string, error) {
+	gcm, err := p.gcmFor(p.keys[p.activeKeyID])
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("falha ao gerar nonce: %w", err)
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	stored := append([]byte{p.activeKeyID}, sealed...)
+	return hex.EncodeToString(stored), nil
+}
+
+func (p *AesGcmProcessor) decrypt(stored string) (string, error) {
+	raw, err := hex.DecodeString(stored)
+	if err != nil {
+		return "", fmt.Errorf("valor guardado não é hex válido: %w", err)
+	}
+	if len(raw) < 1 {
+		return "", fmt.Errorf("valor guardado demasiado curto para conter um key-id")
+	}
+
+	keyID := raw[0]
+	key, ok := p.keys[keyID]
+	if !ok {
+		return "", fmt.Errorf("key-id %d não está configurado", keyID)
+	}
+
+	gcm, err := p.gcmFor(key)
+	if err != nil {
+		return "", err
+	}
+	nonceSize := gcm.NonceSize()
+	body := raw[1:]
+	if len(body) < nonceSize {
+		return "", fmt.Errorf("valor guardado demasiado curto para conter o nonce")
+	}
+	nonce, ciphertext := body[:nonceSize], body[nonceSize:]
+
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("falha ao desencriptar (chave errada ou dados corrompidos): %w", err)
+	}
+	return string(plaintext), nil
+}
+
+func (p *AesGcmProcessor) gcmFor(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao construir cifra AES: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// ParseQuestionEncryptionKeys lê o formato "<key-id>:<chave>,<key-id>:<chave>"
+// usado pela env var QUESTION_ENCRYPTION_KEYS, onde cada chave pode vir em
+// hex ou base64 (testamos hex primeiro, por ser o formato mais comum em
+// ferramentas de geração de chaves AES).
+func ParseQuestionEncryptionKeys(raw string) (map[byte][]byte, error) {
+	keys := make(map[byte][]byte)
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("entrada de chave inválida (esperado 'key-id:chave'): %q", entry)
+		}
+
+		idNum, err := ParseKeyID(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("key-id inválido em %q: %w", entry, err)
+		}
+
+		key, err := decodeKey(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("chave inválida para key-id %d: %w", idNum, err)
+		}
+		keys[idNum] = key
+	}
+	return keys, nil
+}
+
+// ParseKeyID converte o key-id textual (ex: de QUESTION_ENCRYPTION_ACTIVE_KEY_ID)
+// no byte usado internamente para indexar o conjunto de chaves.
+func ParseKeyID(s string) (byte, error) {
+	var idNum int
+	if _, err := fmt.Sscanf(s, "%d", &idNum); err != nil {
+		return 0, err
+	}
+	if idNum < 0 || idNum > 255 {
+		return 0, fmt.Errorf("key-id %d fora do intervalo de 1 byte", idNum)
+	}
+	return byte(idNum), nil
+}
+
+func decodeKey(s string) ([]byte, error) {
+	if key, err := hex.DecodeString(s); err == nil {
+		return key, nil
+	}
+	if key, err := base64.StdEncoding.DecodeString(s); err == nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("não é hex nem base64 válido")
+}
@@ -1,34 +1,40 @@
 package store
 
 import (
-	"log"
+	"log/slog"
+	"os"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres" // Driver do Postgres
 	_ "github.com/golang-migrate/migrate/v4/source/file"       // Driver para ler de arquivos
 )
 
-// RunMigrations executa as migrações do banco de dados
-func RunMigrations(connStr string) {
+// RunMigrations executa as migrações do banco de dados. Falhas aqui são
+// fatais (a aplicação não arranca com o schema desatualizado), mas passam
+// pelo logger em vez de log.Fatalf para manter o mesmo formato estruturado
+// do resto da aplicação.
+func RunMigrations(connStr string, logger *slog.Logger) {
 	// A pasta onde os arquivos SQL estão
 	// (Note: 'file://' é necessário)
 	migrationPath := "file://internal/store/migrations"
 
-	log.Println("Iniciando migrações do banco de dados...")
+	logger.Info("iniciando migrações do banco de dados")
 
 	m, err := migrate.New(migrationPath, connStr)
 	if err != nil {
-		log.Fatalf("Falha ao inicializar migração: %v", err)
+		logger.Error("falha ao inicializar migração", "error", err)
+		os.Exit(1)
 	}
 
 	// Executa a migração (sobe a versão)
 	if err := m.Up(); err != nil {
 		if err == migrate.ErrNoChange {
-			log.Println("Migração: Nenhuma mudança detectada. Banco de dados já está atualizado.")
+			logger.Info("migração: nenhuma mudança detectada, banco de dados já está atualizado")
 		} else {
-			log.Fatalf("Falha ao aplicar migração 'up': %v", err)
+			logger.Error("falha ao aplicar migração 'up'", "error", err)
+			os.Exit(1)
 		}
 	} else {
-		log.Println("Migrações do banco de dados aplicadas com sucesso!")
+		logger.Info("migrações do banco de dados aplicadas com sucesso")
 	}
 }
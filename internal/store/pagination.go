@@ -0,0 +1,64 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// NormalizePagination aplica os defaults e limites de page/limit vindos da
+// query string: page mínima 1, limit default 20 com um teto de 100 para
+// impedir que um cliente peça a tabela inteira de uma vez.
+func NormalizePagination(page, limit int) (normPage, normLimit, offset int) {
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = defaultPageLimit
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+	return page, limit, (page - 1) * limit
+}
+
+// NormalizeCursorLimit aplica o mesmo default/teto de NormalizePagination a
+// um limit vindo de um endpoint paginado por cursor em vez de page, para as
+// duas formas de paginação do projeto partilharem os mesmos valores.
+func NormalizeCursorLimit(limit int) int {
+	if limit <= 0 {
+		limit = defaultPageLimit
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+	return limit
+}
+
+// SortClause valida o parâmetro de ordenação (ex: "-criacao", "nome") contra
+// a whitelist de colunas ordenáveis da entidade (sortColumn -> coluna SQL
+// real) e devolve a cláusula ORDER BY já pronta. Isto impede injeção de SQL
+// via a chave de ordenação, que vem diretamente da query string do cliente.
+// Um sort vazio devolve defaultClause sem validação.
+func SortClause(sort string, allowedColumns map[string]string, defaultClause string) (string, error) {
+	if sort == "" {
+		return defaultClause, nil
+	}
+
+	direction := "ASC"
+	column := sort
+	if strings.HasPrefix(sort, "-") {
+		direction = "DESC"
+		column = strings.TrimPrefix(sort, "-")
+	}
+
+	dbColumn, ok := allowedColumns[column]
+	if !ok {
+		return "", fmt.Errorf("campo de ordenação não suportado: %q", column)
+	}
+	return fmt.Sprintf("%s %s", dbColumn, direction), nil
+}
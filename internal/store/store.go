@@ -4,10 +4,11 @@ package store
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 
 	"github.com/jmoiron/sqlx" // <--- ADICIONE ESTE
 	_ "github.com/lib/pq"
+	"quizz-core/internal/metrics"
 )
 
 // Store agora usa sqlx.DB
@@ -15,8 +16,10 @@ type Store struct {
 	DB *sqlx.DB // <--- MUDANÇA AQUI (de sql.DB para sqlx.DB)
 }
 
-// NewPostgresStore cria uma nova conexão com o banco de dados
-func NewPostgresStore(connStr string) (*Store, error) {
+// NewPostgresStore cria uma nova conexão com o banco de dados. O logger é
+// apenas usado para a mensagem de arranque; chamadas por-pedido (Ping,
+// repositórios) recebem o seu próprio logger via logging.FromContext(ctx).
+func NewPostgresStore(connStr string, logger *slog.Logger) (*Store, error) {
 	// Usamos sqlx.Open em vez de sql.Open
 	db, err := sqlx.Open("postgres", connStr) // <--- MUDANÇA AQUI
 	if err != nil {
@@ -28,7 +31,7 @@ func NewPostgresStore(connStr string) (*Store, error) {
 		return nil, fmt.Errorf("falha ao pingar banco de dados: %w", err)
 	}
 
-	log.Println("Conectado ao banco de dados com sucesso! (usando sqlx)")
+	logger.Info("conectado ao banco de dados com sucesso (usando sqlx)")
 
 	return &Store{
 		DB: db,
@@ -36,5 +39,16 @@ func NewPostgresStore(connStr string) (*Store, error) {
 }
 func (s *Store) Ping(ctx context.Context) error {
 	// Usamos PingContext para respeitar timeouts
+	s.SamplePoolMetrics()
 	return s.DB.PingContext(ctx)
 }
+
+// SamplePoolMetrics lê sqlx.DB.Stats() e atualiza os gauges do pool de
+// conexões; chamado a cada health check para manter os gauges frescos sem
+// precisar de uma goroutine própria.
+func (s *Store) SamplePoolMetrics() {
+	stats := s.DB.Stats()
+	metrics.DBOpenConnections.Set(float64(stats.OpenConnections))
+	metrics.DBInUse.Set(float64(stats.InUse))
+	metrics.DBIdle.Set(float64(stats.Idle))
+}
@@ -0,0 +1,154 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+
+	"quizz-core/internal/llm"
+	"quizz-core/internal/models"
+	"quizz-core/internal/repository/submission"
+)
+
+type mockCommentRepo struct {
+	listBySubmissionFn    func(ctx context.Context, submissionID int, page models.CommentCursorPage) ([]models.QAComment, error)
+	createForSubmissionFn func(ctx context.Context, submissionID int, req models.CreateCommentRequest) (*models.QAComment, error)
+}
+
+func (m *mockCommentRepo) ListByQuiz(ctx context.Context, quizID int, page models.CommentCursorPage) ([]models.QAComment, error) {
+	return nil, nil
+}
+
+func (m *mockCommentRepo) ListBySubmission(ctx context.Context, submissionID int, page models.CommentCursorPage) ([]models.QAComment, error) {
+	return m.listBySubmissionFn(ctx, submissionID, page)
+}
+
+func (m *mockCommentRepo) CreateForQuiz(ctx context.Context, quizID int, req models.CreateCommentRequest) (*models.QAComment, error) {
+	return nil, nil
+}
+
+func (m *mockCommentRepo) CreateForSubmission(ctx context.Context, submissionID int, req models.CreateCommentRequest) (*models.QAComment, error) {
+	return m.createForSubmissionFn(ctx, submissionID, req)
+}
+
+func (m *mockCommentRepo) Get(ctx context.Context, id int) (*models.QAComment, error) {
+	return nil, nil
+}
+
+func (m *mockCommentRepo) Delete(ctx context.Context, id int) error {
+	return nil
+}
+
+// Não há mockgen disponível neste projeto, então os mocks abaixo são
+// escritos à mão: cada um implementa a interface correspondente com campos
+// de função configuráveis por teste.
+
+type mockQuizRepo struct {
+	saveGeneratedQuizFn       func(ctx context.Context, req models.CreateQuizRequest, llmQuestions []llm.LLMQuestionResponse) (*models.Quiz, []models.Pergunta, error)
+	deactivateQuizFn          func(ctx context.Context, quizID int) (int64, error)
+	getActiveQuizzesByThemeFn func(ctx context.Context, themeID int) ([]models.Quiz, error)
+	listQuizzesFn             func(ctx context.Context, filter models.QuizFilter) (models.PagedResponse[models.Quiz], error)
+}
+
+func (m *mockQuizRepo) SaveGeneratedQuiz(ctx context.Context, req models.CreateQuizRequest, llmQuestions []llm.LLMQuestionResponse) (*models.Quiz, []models.Pergunta, error) {
+	return m.saveGeneratedQuizFn(ctx, req, llmQuestions)
+}
+
+func (m *mockQuizRepo) DeactivateQuiz(ctx context.Context, quizID int) (int64, error) {
+	return m.deactivateQuizFn(ctx, quizID)
+}
+
+func (m *mockQuizRepo) GetActiveQuizzesByTheme(ctx context.Context, themeID int) ([]models.Quiz, error) {
+	return m.getActiveQuizzesByThemeFn(ctx, themeID)
+}
+
+func (m *mockQuizRepo) ListQuizzes(ctx context.Context, filter models.QuizFilter) (models.PagedResponse[models.Quiz], error) {
+	return m.listQuizzesFn(ctx, filter)
+}
+
+type mockSubmissionRepo struct {
+	getQuizAnswersFn       func(ctx context.Context, quizID int) (map[string]submission.QuestionAnswerInfo, error)
+	saveSubmissionStatsFn  func(ctx context.Context, sub models.Submissao, dadas []models.RespostaDada, difs []models.Dificuldade, idempotency *models.IdempotencyInfo) (*models.Submissao, error)
+	getSubmissionDetailsFn func(ctx context.Context, submissionID int) (*models.SubmissionDetailResponse, error)
+	getUserStatsFn         func(ctx context.Context, userID int) (*models.UserStatsResponse, error)
+	listUserSubmissionsFn  func(ctx context.Context, userID int, pagination models.Pagination) (models.PagedResponse[models.UserSubmissionHistoryResponse], error)
+	getIdempotencyRecordFn func(ctx context.Context, userID int, key string) (*models.IdempotencyRecord, error)
+	getSubmissionSummaryFn func(ctx context.Context, submissionID int) (*models.SubmissionResponse, error)
+	saveDraftAnswersFn     func(ctx context.Context, userID, quizID int, answers map[int]int) error
+	getDraftAnswersFn      func(ctx context.Context, userID, quizID int) (map[int]int, error)
+	getSubmissionOwnerFn   func(ctx context.Context, submissionID int) (int, error)
+}
+
+func (m *mockSubmissionRepo) GetQuizAnswers(ctx context.Context, quizID int) (map[string]submission.QuestionAnswerInfo, error) {
+	return m.getQuizAnswersFn(ctx, quizID)
+}
+
+func (m *mockSubmissionRepo) SaveSubmissionStats(ctx context.Context, sub models.Submissao, dadas []models.RespostaDada, difs []models.Dificuldade, idempotency *models.IdempotencyInfo) (*models.Submissao, error) {
+	return m.saveSubmissionStatsFn(ctx, sub, dadas, difs, idempotency)
+}
+
+func (m *mockSubmissionRepo) GetSubmissionDetails(ctx context.Context, submissionID int) (*models.SubmissionDetailResponse, error) {
+	return m.getSubmissionDetailsFn(ctx, submissionID)
+}
+
+func (m *mockSubmissionRepo) GetUserStats(ctx context.Context, userID int) (*models.UserStatsResponse, error) {
+	return m.getUserStatsFn(ctx, userID)
+}
+
+func (m *mockSubmissionRepo) ListUserSubmissions(ctx context.Context, userID int, pagination models.Pagination) (models.PagedResponse[models.UserSubmissionHistoryResponse], error) {
+	return m.listUserSubmissionsFn(ctx, userID, pagination)
+}
+
+func (m *mockSubmissionRepo) GetIdempotencyRecord(ctx context.Context, userID int, key string) (*models.IdempotencyRecord, error) {
+	if m.getIdempotencyRecordFn == nil {
+		return nil, sql.ErrNoRows
+	}
+	return m.getIdempotencyRecordFn(ctx, userID, key)
+}
+
+func (m *mockSubmissionRepo) GetSubmissionSummary(ctx context.Context, submissionID int) (*models.SubmissionResponse, error) {
+	return m.getSubmissionSummaryFn(ctx, submissionID)
+}
+
+func (m *mockSubmissionRepo) SaveDraftAnswers(ctx context.Context, userID, quizID int, answers map[int]int) error {
+	return m.saveDraftAnswersFn(ctx, userID, quizID, answers)
+}
+
+func (m *mockSubmissionRepo) GetDraftAnswers(ctx context.Context, userID, quizID int) (map[int]int, error) {
+	if m.getDraftAnswersFn == nil {
+		return map[int]int{}, nil
+	}
+	return m.getDraftAnswersFn(ctx, userID, quizID)
+}
+
+func (m *mockSubmissionRepo) GetSubmissionOwner(ctx context.Context, submissionID int) (int, error) {
+	return m.getSubmissionOwnerFn(ctx, submissionID)
+}
+
+type mockThemeRepo struct {
+	getAllActiveThemesFn func(ctx context.Context) ([]models.Tema, error)
+}
+
+func (m *mockThemeRepo) GetAllActiveThemes(ctx context.Context) ([]models.Tema, error) {
+	return m.getAllActiveThemesFn(ctx)
+}
+
+type mockPinger struct {
+	pingFn func(ctx context.Context) error
+}
+
+func (m *mockPinger) Ping(ctx context.Context) error {
+	return m.pingFn(ctx)
+}
+
+type mockLLMProvider struct {
+	generateQuizFn func(ctx context.Context, prompt string) (string, error)
+	pingFn         func(ctx context.Context) error
+}
+
+func (m *mockLLMProvider) GenerateQuiz(ctx context.Context, prompt string) (string, error) {
+	return m.generateQuizFn(ctx, prompt)
+}
+
+func (m *mockLLMProvider) Ping(ctx context.Context) error {
+	return m.pingFn(ctx)
+}
@@ -0,0 +1,917 @@
+// Package usecase compõe os repositórios (quiz, submission, theme) e o
+// provider de LLM na lógica de negócio da aplicação, para que a camada api
+// dependa apenas de uma interface (QuizUsecase) e não de implementações
+// concretas de base de dados.
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"quizz-core/internal/llm"
+	"quizz-core/internal/logging"
+	"quizz-core/internal/metrics"
+	"quizz-core/internal/models"
+	"quizz-core/internal/repository/comment"
+	"quizz-core/internal/repository/paper"
+	"quizz-core/internal/repository/quiz"
+	"quizz-core/internal/repository/submission"
+	"quizz-core/internal/repository/theme"
+	"quizz-core/internal/store"
+)
+
+// --- Erros Personalizados de Negócios ---
+var (
+	ErrNotFound     = errors.New("recurso não encontrado")
+	ErrInvalidInput = errors.New("input inválido")
+	ErrUnavailable  = errors.New("serviço indisponível")
+	ErrForbidden    = errors.New("acesso negado")
+)
+
+// ------------------------------------
+
+// QuizStreamChunk é o que o handler SSE recebe do usecase: ou uma pergunta
+// já pronta para ser enviada ao cliente, ou o sinal de que terminámos.
+type QuizStreamChunk struct {
+	Wrapper  *llm.LLMWrapper
+	Question *llm.LLMQuestionResponse
+	Done     bool
+}
+
+// Pinger é satisfeito por qualquer dependência que saiba responder a um
+// health check (hoje, *store.Store).
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// QuizUsecase é a interface que a camada api depende. *quizUsecase é a
+// única implementação hoje, mas qualquer mock que a satisfaça serve para
+// testar os handlers sem uma base de dados real.
+type QuizUsecase interface {
+	CreateQuiz(ctx context.Context, req models.CreateQuizRequest) (*models.RawQuizResponse, error)
+	CreateQuizStream(ctx context.Context, req models.CreateQuizRequest) (<-chan QuizStreamChunk, <-chan error)
+	SubmitAnswers(ctx context.Context, req models.SubmissionRequest) (*models.SubmissionResponse, error)
+	SaveDraft(ctx context.Context, req models.DraftRequest) error
+	GetUserStats(ctx context.Context, userIDStr string) (*models.UserStatsResponse, error)
+	ListUserSubmissions(ctx context.Context, userIDStr string, pagination models.Pagination) (models.PagedResponse[models.UserSubmissionHistoryResponse], error)
+	GetAllActiveThemes(ctx context.Context) ([]models.Tema, error)
+	DeactivateQuiz(ctx context.Context, quizIDStr string) error
+	GetSubmissionDetails(ctx context.Context, submissionIDStr string, actorUserID int, actorIsAdmin bool) (*models.SubmissionDetailResponse, error)
+	GetActiveQuizzesByTheme(ctx context.Context, themeIDStr string) ([]models.Quiz, error)
+	ListQuizzesByTheme(ctx context.Context, themeIDStr string, pagination models.Pagination) (models.PagedResponse[models.Quiz], error)
+	CreatePaper(ctx context.Context, req models.CreatePaperRequest) (*models.QuizPaper, error)
+	GetPaper(ctx context.Context, paperIDStr string) (*models.QuizPaper, []models.QuizPaperQuestion, error)
+	UpdatePaper(ctx context.Context, paperIDStr string, req models.UpdatePaperRequest) (*models.QuizPaper, error)
+	DeletePaper(ctx context.Context, paperIDStr string) error
+	InstantiatePaper(ctx context.Context, paperIDStr string, req models.InstantiatePaperRequest) (*models.Quiz, []models.Pergunta, error)
+	ListQuizComments(ctx context.Context, quizIDStr string, page models.CommentCursorPage) (models.CommentPage, error)
+	CreateQuizComment(ctx context.Context, quizIDStr string, req models.CreateCommentRequest) (*models.QAComment, error)
+	DeleteQuizComment(ctx context.Context, quizIDStr, commentIDStr string, actorUserID int, actorIsAdmin bool) error
+	ExportQuizComments(ctx context.Context, quizIDStr string) ([]models.QAComment, error)
+	ListSubmissionComments(ctx context.Context, submissionIDStr string, page models.CommentCursorPage, actorUserID int, actorIsAdmin bool) (models.CommentPage, error)
+	CreateSubmissionComment(ctx context.Context, submissionIDStr string, req models.CreateCommentRequest, actorUserID int, actorIsAdmin bool) (*models.QAComment, error)
+	DeleteSubmissionComment(ctx context.Context, submissionIDStr, commentIDStr string, actorUserID int, actorIsAdmin bool) error
+	CheckHealth(ctx context.Context) models.HealthResponse
+}
+
+// quizUsecase is our business logic struct
+type quizUsecase struct {
+	quizRepo       quiz.Repository
+	submissionRepo submission.Repository
+	themeRepo      theme.Repository
+	paperRepo      paper.Repository
+	commentRepo    comment.Repository
+	db             Pinger       // Para o health check (ver CheckHealth)
+	llmClient      llm.Provider // The LLM provider (Ollama, OpenAI-compatible, mock, ...)
+}
+
+// NewQuizUsecase is the constructor
+func NewQuizUsecase(quizRepo quiz.Repository, submissionRepo submission.Repository, themeRepo theme.Repository, paperRepo paper.Repository, commentRepo comment.Repository, db Pinger, provider llm.Provider) QuizUsecase {
+	return &quizUsecase{
+		quizRepo:       quizRepo,
+		submissionRepo: submissionRepo,
+		themeRepo:      themeRepo,
+		paperRepo:      paperRepo,
+		commentRepo:    commentRepo,
+		db:             db,
+		llmClient:      provider,
+	}
+}
+
+// CreateQuiz agora apenas chama a LLM e retorna o JSON cru. Se o pedido
+// trouxer PaperID, instanciamos o paper em vez de gerar perguntas novas via
+// LLM (ver InstantiatePaper), para instrutores poderem distribuir quizzes
+// pré-autorados.
+func (u *quizUsecase) CreateQuiz(ctx context.Context, req models.CreateQuizRequest) (*models.RawQuizResponse, error) { // <-- Retorna RawQuizResponse
+	if req.PaperID != "" {
+		return u.createQuizFromPaper(ctx, req)
+	}
+
+	// 1. Chamar a LLM para obter a string JSON
+	prompt := llm.BuildQuizPrompt(req.Theme, req.WrongSubjects)
+	rawJsonString, err := u.llmClient.GenerateQuiz(ctx, prompt)
+	if err != nil {
+		// Mantém o log de erro interno
+		logging.FromContext(ctx).Error("erro ao gerar quiz via LLM", "error", err)
+		if errors.Is(err, llm.ErrCircuitOpen) {
+			return nil, fmt.Errorf("%w: provider de LLM em circuit-breaker aberto", ErrUnavailable)
+		}
+		// Retorna um erro genérico para o handler (que dará 500)
+		return nil, fmt.Errorf("usecase error calling LLM: %w", err)
+	}
+
+	// 2. Formatar a nova resposta (RawQuizResponse)
+	response := &models.RawQuizResponse{
+		UserID:     req.UserID,    // Passa o UserID original
+		RawLLMJson: rawJsonString, // Passa a string JSON crua
+	}
+
+	return response, nil
+}
+
+// createQuizFromPaper instancia o paper indicado em req.PaperID (ver
+// paper.Repository.InstantiatePaper) e devolve o quiz resultante no mesmo
+// formato RawQuizResponse que CreateQuiz devolveria para um quiz gerado pela
+// LLM, para o handler não precisar saber qual dos dois caminhos foi usado.
+func (u *quizUsecase) createQuizFromPaper(ctx context.Context, req models.CreateQuizRequest) (*models.RawQuizResponse, error) {
+	paperID, err := strconv.Atoi(req.PaperID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: paper_id inválido", ErrInvalidInput)
+	}
+	userID, err := strconv.Atoi(req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: user_id inválido", ErrInvalidInput)
+	}
+
+	savedQuiz, perguntas, err := u.paperRepo.InstantiatePaper(ctx, paperID, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: paper com id %d não encontrado", ErrNotFound, paperID)
+		}
+		if errors.Is(err, paper.ErrNoQuestions) {
+			return nil, fmt.Errorf("%w: paper com id %d não tem perguntas", ErrInvalidInput, paperID)
+		}
+		return nil, fmt.Errorf("falha ao instanciar paper no usecase: %w", err)
+	}
+
+	answerMap, err := u.submissionRepo.GetQuizAnswers(ctx, savedQuiz.ID)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao buscar gabarito do quiz instanciado: %w", err)
+	}
+
+	wrapper := llm.LLMWrapper{Title: savedQuiz.Nome}
+	for _, pergunta := range perguntas {
+		info, ok := answerMap[strconv.Itoa(pergunta.ID)]
+		if !ok {
+			continue
+		}
+		options := make([]string, 0, len(info.OptionsMap))
+		for opt := range info.OptionsMap {
+			options = append(options, opt)
+		}
+		explicacao := ""
+		if pergunta.Explicacao != nil {
+			explicacao = *pergunta.Explicacao
+		}
+		wrapper.Questions = append(wrapper.Questions, llm.LLMQuestionResponse{
+			Subject:       info.Assunto,
+			QuestionText:  pergunta.Corpo,
+			Options:       options,
+			CorrectAnswer: info.CorrectOptionText,
+			Explanation:   explicacao,
+		})
+	}
+
+	rawJSON, err := json.Marshal(wrapper)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao serializar quiz instanciado do paper %d: %w", paperID, err)
+	}
+
+	return &models.RawQuizResponse{UserID: req.UserID, RawLLMJson: string(rawJSON)}, nil
+}
+
+// CreateQuizStream chama a LLM em modo streaming e vai repassando cada
+// pergunta assim que ela sai completa e válida do buffer do LLM, em vez de
+// esperar os até 3 minutos que GenerateQuiz normalmente bloqueia. Só
+// funciona se o provider configurado implementar llm.StreamingProvider
+// (hoje, apenas o Ollama).
+func (u *quizUsecase) CreateQuizStream(ctx context.Context, req models.CreateQuizRequest) (<-chan QuizStreamChunk, <-chan error) {
+	out := make(chan QuizStreamChunk)
+	errs := make(chan error, 1)
+
+	streamer, ok := u.llmClient.(llm.StreamingProvider)
+	if !ok {
+		close(out)
+		errs <- fmt.Errorf("o provider de LLM configurado não suporta streaming")
+		close(errs)
+		return out, errs
+	}
+
+	prompt := llm.BuildQuizPrompt(req.Theme, req.WrongSubjects)
+	llmChunks, llmErrs := streamer.GenerateQuizStream(ctx, prompt)
+
+	go func() {
+		defer close(out)
+		defer close(errs)
+
+		for chunk := range llmChunks {
+			select {
+			case out <- QuizStreamChunk{
+				Wrapper:  chunk.Wrapper,
+				Question: chunk.Question,
+				Done:     chunk.Done,
+			}:
+			case <-ctx.Done():
+				// O cliente abortou o streaming (ver CreateQuizStreamHandler):
+				// desistimos de repassar chunks em vez de bloquear para
+				// sempre à espera de um leitor que já não vai aparecer.
+				return
+			}
+		}
+		if err, ok := <-llmErrs; ok && err != nil {
+			select {
+			case errs <- fmt.Errorf("falha ao gerar quiz em streaming: %w", err):
+			case <-ctx.Done():
+			}
+		}
+	}()
+
+	return out, errs
+}
+
+// replaySubmission devolve a resposta de uma submissão já gravada para a
+// Idempotency-Key de req, usada tanto quando um retry sequencial encontra o
+// registo logo no início de SubmitAnswers como quando ele só aparece depois,
+// por termos perdido a corrida contra um pedido concorrente em
+// SaveSubmissionStats (ver submission.ErrIdempotencyConflict). Em ambos os
+// casos, um hash diferente do que foi gravado significa que a chave está a
+// ser reutilizada com um pedido diferente, não um retry legítimo.
+func (u *quizUsecase) replaySubmission(ctx context.Context, req models.SubmissionRequest, existing *models.IdempotencyRecord) (*models.SubmissionResponse, error) {
+	if existing.RequestHash != req.RequestHash {
+		return nil, fmt.Errorf("%w: idempotency key já usada com um pedido diferente", ErrInvalidInput)
+	}
+	return u.submissionRepo.GetSubmissionSummary(ctx, existing.SubmissaoID)
+}
+
+// SubmitAnswers é a lógica de negócios para processar as respostas de um quiz
+func (u *quizUsecase) SubmitAnswers(ctx context.Context, req models.SubmissionRequest) (*models.SubmissionResponse, error) {
+
+	// 1. Converter IDs de String para Int
+	quizID, err := strconv.Atoi(req.QuizID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: quiz_id inválido", ErrInvalidInput)
+	}
+	userID, err := strconv.Atoi(req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: user_id inválido", ErrInvalidInput)
+	}
+
+	// 1.1 Se o cliente enviou Idempotency-Key e já processámos esta chave
+	// para este utilizador, devolve a resposta original em vez de reprocessar
+	if req.IdempotencyKey != "" {
+		existing, err := u.submissionRepo.GetIdempotencyRecord(ctx, userID, req.IdempotencyKey)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("falha ao verificar idempotency key: %w", err)
+		}
+		if err == nil {
+			return u.replaySubmission(ctx, req, existing)
+		}
+	}
+
+	// 2. Buscar o Gabarito (agora retorna o mapa complexo)
+	answerMap, err := u.submissionRepo.GetQuizAnswers(ctx, quizID)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao buscar gabarito: %w", err)
+	}
+	if len(answerMap) == 0 {
+		return nil, fmt.Errorf("%w: quiz com id %d não encontrado", ErrNotFound, quizID)
+	}
+
+	// 2.1 Mesclar o draft gravado com as respostas explícitas deste pedido:
+	// perguntas não reenviadas agora usam a última resposta do draft, para um
+	// cliente que perdeu a ligação a meio não perder o progresso
+	draftAnswers, err := u.submissionRepo.GetDraftAnswers(ctx, userID, quizID)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao buscar draft da submissão: %w", err)
+	}
+	merged := make(map[int]int, len(draftAnswers)+len(req.Answers))
+	for perguntaID, respostaID := range draftAnswers {
+		merged[perguntaID] = respostaID
+	}
+	for _, userAnswer := range req.Answers {
+		questionID, err := strconv.Atoi(userAnswer.QuestionID)
+		if err != nil {
+			logging.FromContext(ctx).Warn("question_id inválido recebido", "question_id", userAnswer.QuestionID)
+			continue
+		}
+		questionInfo, ok := answerMap[userAnswer.QuestionID]
+		if !ok {
+			logging.FromContext(ctx).Warn("resposta recebida para pergunta que não pertence ao quiz", "question_id", userAnswer.QuestionID)
+			continue
+		}
+		respostaID, ok := questionInfo.OptionsMap[userAnswer.SelectedOption]
+		if !ok {
+			logging.FromContext(ctx).Warn("opção não encontrada para a pergunta", "option", userAnswer.SelectedOption, "question_id", questionID)
+			continue
+		}
+		merged[questionID] = respostaID
+	}
+
+	// 3. Inicializar contadores e listas para salvar
+	correctCount := 0
+	totalCount := len(answerMap)
+	var respostasDadas []models.RespostaDada
+	var dificuldades []models.Dificuldade
+
+	// 4. Iterar sobre as Respostas Mescladas (draft + pedido) e Comparar
+	for perguntaID, respostaID := range merged {
+		questionInfo, ok := answerMap[strconv.Itoa(perguntaID)]
+		if !ok {
+			logging.FromContext(ctx).Warn("resposta mesclada para pergunta que não pertence ao quiz", "question_id", perguntaID)
+			continue
+		}
+
+		isCorrect := respostaID == questionInfo.CorrectOptionID
+		selectedAnswerID := respostaID
+
+		if isCorrect {
+			correctCount++
+		} else {
+			dificuldades = append(dificuldades, models.Dificuldade{
+				Assunto: &questionInfo.Assunto,
+			})
+		}
+
+		respostasDadas = append(respostasDadas, models.RespostaDada{
+			PerguntaID:         perguntaID,
+			CorretaNaSubmissao: &isCorrect,
+			RespostaID:         &selectedAnswerID,
+		})
+	}
+
+	// 5. Calcular Pontuação
+	var score float64 = 0
+	if totalCount > 0 {
+		score = (float64(correctCount) / float64(totalCount)) * 100.0
+	}
+
+	// 6. Preparar o objeto 'Submissao' para salvar
+	submissaoParaSalvar := models.Submissao{
+		DataHora:     time.Now(),
+		Pontuacao:    score,
+		UtilizadorID: userID,
+		QuizzID:      quizID,
+	}
+
+	// 6.1 Se houver Idempotency-Key, o repositório grava o registo de replay
+	// na mesma transação que salva a submissão
+	var idempotency *models.IdempotencyInfo
+	if req.IdempotencyKey != "" {
+		idempotency = &models.IdempotencyInfo{Key: req.IdempotencyKey, RequestHash: req.RequestHash}
+	}
+
+	// 7. Salvar Tudo no DB (em uma única transação, que também promove o
+	// draft a submissão final apagando-o)
+	savedSub, err := u.submissionRepo.SaveSubmissionStats(ctx, submissaoParaSalvar, respostasDadas, dificuldades, idempotency)
+	if err != nil {
+		if errors.Is(err, submission.ErrIdempotencyConflict) {
+			// Um pedido concorrente com a mesma Idempotency-Key venceu a
+			// corrida e já gravou a submissão; devolve a resposta dele em vez
+			// de propagar o conflito como erro ao cliente.
+			logging.FromContext(ctx).Info("conflito de idempotência concorrente, devolvendo submissão da vencedora",
+				"user_id", userID, "idempotency_key", req.IdempotencyKey)
+			existing, getErr := u.submissionRepo.GetIdempotencyRecord(ctx, userID, req.IdempotencyKey)
+			if getErr != nil {
+				return nil, fmt.Errorf("falha ao recuperar submissão concorrente após conflito de idempotência: %w", getErr)
+			}
+			return u.replaySubmission(ctx, req, existing)
+		}
+		return nil, fmt.Errorf("falha ao salvar estatísticas da submissão: %w", err)
+	}
+	metrics.QuizSubmissionScore.Observe(savedSub.Pontuacao)
+
+	// 8. Formatar a Resposta da API
+	response := &models.SubmissionResponse{
+		SubmissionID: savedSub.ID,
+		Score:        savedSub.Pontuacao,
+		CorrectCount: correctCount,
+		TotalCount:   totalCount,
+		Message:      fmt.Sprintf("Submissão bem-sucedida! Acertou %d de %d.", correctCount, totalCount),
+	}
+
+	return response, nil
+}
+
+// SaveDraft é a lógica de negócios para gravar respostas parciais de um quiz
+// que o utilizador ainda não submeteu. Resolve o texto de cada opção contra
+// o gabarito do quiz para já guardar o draft com resposta_id (tal como uma
+// submissão final), o que é o que SubmitAnswers depois lê de volta ao
+// mesclar o draft com o pedido de submissão.
+func (u *quizUsecase) SaveDraft(ctx context.Context, req models.DraftRequest) error {
+	quizID, err := strconv.Atoi(req.QuizID)
+	if err != nil {
+		return fmt.Errorf("%w: quiz_id inválido", ErrInvalidInput)
+	}
+	userID, err := strconv.Atoi(req.UserID)
+	if err != nil {
+		return fmt.Errorf("%w: user_id inválido", ErrInvalidInput)
+	}
+	if len(req.Answers) == 0 {
+		return fmt.Errorf("%w: draft sem respostas", ErrInvalidInput)
+	}
+
+	answerMap, err := u.submissionRepo.GetQuizAnswers(ctx, quizID)
+	if err != nil {
+		return fmt.Errorf("falha ao buscar gabarito para draft: %w", err)
+	}
+	if len(answerMap) == 0 {
+		return fmt.Errorf("%w: quiz com id %d não encontrado", ErrNotFound, quizID)
+	}
+
+	resolved := make(map[int]int, len(req.Answers))
+	for _, userAnswer := range req.Answers {
+		questionID, err := strconv.Atoi(userAnswer.QuestionID)
+		if err != nil {
+			logging.FromContext(ctx).Warn("question_id inválido recebido no draft", "question_id", userAnswer.QuestionID)
+			continue
+		}
+		questionInfo, ok := answerMap[userAnswer.QuestionID]
+		if !ok {
+			logging.FromContext(ctx).Warn("resposta de draft para pergunta que não pertence ao quiz", "question_id", userAnswer.QuestionID)
+			continue
+		}
+		respostaID, ok := questionInfo.OptionsMap[userAnswer.SelectedOption]
+		if !ok {
+			logging.FromContext(ctx).Warn("opção não encontrada para a pergunta do draft", "option", userAnswer.SelectedOption, "question_id", questionID)
+			continue
+		}
+		resolved[questionID] = respostaID
+	}
+	if len(resolved) == 0 {
+		return fmt.Errorf("%w: nenhuma resposta válida no draft", ErrInvalidInput)
+	}
+
+	if err := u.submissionRepo.SaveDraftAnswers(ctx, userID, quizID, resolved); err != nil {
+		return fmt.Errorf("falha ao salvar draft: %w", err)
+	}
+	return nil
+}
+
+// GetUserStats é a lógica de negócios para buscar as estatísticas
+func (u *quizUsecase) GetUserStats(ctx context.Context, userIDStr string) (*models.UserStatsResponse, error) {
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: user_id inválido", ErrInvalidInput)
+	}
+	stats, err := u.submissionRepo.GetUserStats(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao buscar estatísticas no usecase: %w", err)
+	}
+	return stats, nil
+}
+
+// ListUserSubmissions é a lógica de negócios para buscar o histórico,
+// paginada/pesquisável (q/page/limit/sort), usada pelo endpoint de listagem.
+func (u *quizUsecase) ListUserSubmissions(ctx context.Context, userIDStr string, pagination models.Pagination) (models.PagedResponse[models.UserSubmissionHistoryResponse], error) {
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		return models.PagedResponse[models.UserSubmissionHistoryResponse]{}, fmt.Errorf("%w: user_id inválido", ErrInvalidInput)
+	}
+	paged, err := u.submissionRepo.ListUserSubmissions(ctx, userID, pagination)
+	if err != nil {
+		if errors.Is(err, submission.ErrInvalidSort) {
+			return models.PagedResponse[models.UserSubmissionHistoryResponse]{}, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+		}
+		return models.PagedResponse[models.UserSubmissionHistoryResponse]{}, fmt.Errorf("falha ao listar histórico no usecase: %w", err)
+	}
+	return paged, nil
+}
+
+// GetAllActiveThemes é a lógica de negócios para buscar os temas
+func (u *quizUsecase) GetAllActiveThemes(ctx context.Context) ([]models.Tema, error) {
+	temas, err := u.themeRepo.GetAllActiveThemes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao buscar temas ativos no usecase: %w", err)
+	}
+	return temas, nil
+}
+
+// DeactivateQuiz é a lógica de negócios para o soft-delete
+func (u *quizUsecase) DeactivateQuiz(ctx context.Context, quizIDStr string) error {
+	quizID, err := strconv.Atoi(quizIDStr)
+	if err != nil {
+		return fmt.Errorf("%w: quiz_id inválido", ErrInvalidInput)
+	}
+	rowsAffected, err := u.quizRepo.DeactivateQuiz(ctx, quizID)
+	if err != nil {
+		return fmt.Errorf("falha ao desativar quiz no usecase: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: quiz com id %d não encontrado ou já está inativo", ErrNotFound, quizID)
+	}
+	return nil
+}
+
+// GetSubmissionDetails é a lógica de negócios para buscar os detalhes, mas só
+// devolve algo ao dono da submissão (ou a um admin) — ver
+// checkSubmissionOwnership, exatamente como em ListSubmissionComments.
+func (u *quizUsecase) GetSubmissionDetails(ctx context.Context, submissionIDStr string, actorUserID int, actorIsAdmin bool) (*models.SubmissionDetailResponse, error) {
+	submissionID, err := strconv.Atoi(submissionIDStr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: submission_id inválido", ErrInvalidInput)
+	}
+	if err := u.checkSubmissionOwnership(ctx, submissionID, actorUserID, actorIsAdmin); err != nil {
+		return nil, err
+	}
+	details, err := u.submissionRepo.GetSubmissionDetails(ctx, submissionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: submissão com id %d não encontrada", ErrNotFound, submissionID)
+		}
+		return nil, fmt.Errorf("falha ao buscar detalhes da submissão no usecase: %w", err)
+	}
+	return details, nil
+}
+
+// GetActiveQuizzesByTheme é a lógica de negócios para buscar quizzes
+func (u *quizUsecase) GetActiveQuizzesByTheme(ctx context.Context, themeIDStr string) ([]models.Quiz, error) {
+	themeID, err := strconv.Atoi(themeIDStr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: theme_id inválido", ErrInvalidInput)
+	}
+	quizzes, err := u.quizRepo.GetActiveQuizzesByTheme(ctx, themeID)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao buscar quizzes por tema no usecase: %w", err)
+	}
+	return quizzes, nil
+}
+
+// ListQuizzesByTheme é a versão paginada/pesquisável de
+// GetActiveQuizzesByTheme, usada pelo endpoint de listagem com query params
+// (q/page/limit/sort).
+func (u *quizUsecase) ListQuizzesByTheme(ctx context.Context, themeIDStr string, pagination models.Pagination) (models.PagedResponse[models.Quiz], error) {
+	themeID, err := strconv.Atoi(themeIDStr)
+	if err != nil {
+		return models.PagedResponse[models.Quiz]{}, fmt.Errorf("%w: theme_id inválido", ErrInvalidInput)
+	}
+	ativo := true
+	paged, err := u.quizRepo.ListQuizzes(ctx, models.QuizFilter{
+		Pagination:   pagination,
+		ThemeID:      &themeID,
+		Ativo:        &ativo,
+		NomeContains: pagination.Q,
+	})
+	if err != nil {
+		if errors.Is(err, quiz.ErrInvalidSort) {
+			return models.PagedResponse[models.Quiz]{}, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+		}
+		return models.PagedResponse[models.Quiz]{}, fmt.Errorf("falha ao listar quizzes por tema no usecase: %w", err)
+	}
+	return paged, nil
+}
+
+// CreatePaper é a lógica de negócios para criar um quiz paper (banco de
+// questões reutilizável) a partir de perguntas já existentes.
+func (u *quizUsecase) CreatePaper(ctx context.Context, req models.CreatePaperRequest) (*models.QuizPaper, error) {
+	if req.Title == "" {
+		return nil, fmt.Errorf("%w: 'title' não pode estar em branco", ErrInvalidInput)
+	}
+	if len(req.PerguntaIDs) == 0 {
+		return nil, fmt.Errorf("%w: 'pergunta_ids' não pode estar vazio", ErrInvalidInput)
+	}
+
+	created, err := u.paperRepo.CreatePaper(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao criar quiz paper no usecase: %w", err)
+	}
+	return created, nil
+}
+
+// GetPaper é a lógica de negócios para buscar um quiz paper e as suas perguntas
+func (u *quizUsecase) GetPaper(ctx context.Context, paperIDStr string) (*models.QuizPaper, []models.QuizPaperQuestion, error) {
+	paperID, err := strconv.Atoi(paperIDStr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: paper_id inválido", ErrInvalidInput)
+	}
+	found, questions, err := u.paperRepo.GetPaper(ctx, paperID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, fmt.Errorf("%w: paper com id %d não encontrado", ErrNotFound, paperID)
+		}
+		return nil, nil, fmt.Errorf("falha ao buscar quiz paper no usecase: %w", err)
+	}
+	return found, questions, nil
+}
+
+// UpdatePaper é a lógica de negócios para substituir o título/perguntas de
+// um paper, o que incrementa a sua versão (ver paper.Repository.UpdatePaper).
+func (u *quizUsecase) UpdatePaper(ctx context.Context, paperIDStr string, req models.UpdatePaperRequest) (*models.QuizPaper, error) {
+	paperID, err := strconv.Atoi(paperIDStr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: paper_id inválido", ErrInvalidInput)
+	}
+	if req.Title == "" {
+		return nil, fmt.Errorf("%w: 'title' não pode estar em branco", ErrInvalidInput)
+	}
+	if len(req.PerguntaIDs) == 0 {
+		return nil, fmt.Errorf("%w: 'pergunta_ids' não pode estar vazio", ErrInvalidInput)
+	}
+
+	updated, err := u.paperRepo.UpdatePaper(ctx, paperID, req)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: paper com id %d não encontrado", ErrNotFound, paperID)
+		}
+		return nil, fmt.Errorf("falha ao atualizar quiz paper no usecase: %w", err)
+	}
+	return updated, nil
+}
+
+// DeletePaper é a lógica de negócios para o soft-delete de um paper
+func (u *quizUsecase) DeletePaper(ctx context.Context, paperIDStr string) error {
+	paperID, err := strconv.Atoi(paperIDStr)
+	if err != nil {
+		return fmt.Errorf("%w: paper_id inválido", ErrInvalidInput)
+	}
+	rowsAffected, err := u.paperRepo.DeletePaper(ctx, paperID)
+	if err != nil {
+		return fmt.Errorf("falha ao desativar quiz paper no usecase: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: paper com id %d não encontrado ou já está inativo", ErrNotFound, paperID)
+	}
+	return nil
+}
+
+// InstantiatePaper é a lógica de negócios para materializar um Quiz runnable
+// a partir de um paper, para um utilizador específico.
+func (u *quizUsecase) InstantiatePaper(ctx context.Context, paperIDStr string, req models.InstantiatePaperRequest) (*models.Quiz, []models.Pergunta, error) {
+	paperID, err := strconv.Atoi(paperIDStr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: paper_id inválido", ErrInvalidInput)
+	}
+	userID, err := strconv.Atoi(req.UserID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: user_id inválido", ErrInvalidInput)
+	}
+
+	instantiated, perguntas, err := u.paperRepo.InstantiatePaper(ctx, paperID, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, fmt.Errorf("%w: paper com id %d não encontrado", ErrNotFound, paperID)
+		}
+		if errors.Is(err, paper.ErrNoQuestions) {
+			return nil, nil, fmt.Errorf("%w: paper com id %d não tem perguntas", ErrInvalidInput, paperID)
+		}
+		return nil, nil, fmt.Errorf("falha ao instanciar paper no usecase: %w", err)
+	}
+	return instantiated, perguntas, nil
+}
+
+// ListQuizComments é a lógica de negócios para listar os comentários de um
+// quiz, paginados por cursor (ver models.CommentCursorPage).
+func (u *quizUsecase) ListQuizComments(ctx context.Context, quizIDStr string, page models.CommentCursorPage) (models.CommentPage, error) {
+	quizID, err := strconv.Atoi(quizIDStr)
+	if err != nil {
+		return models.CommentPage{}, fmt.Errorf("%w: quiz_id inválido", ErrInvalidInput)
+	}
+	page.Limit = store.NormalizeCursorLimit(page.Limit)
+
+	items, err := u.commentRepo.ListByQuiz(ctx, quizID, page)
+	if err != nil {
+		return models.CommentPage{}, fmt.Errorf("falha ao listar comentários do quiz no usecase: %w", err)
+	}
+	return buildCommentPage(items, page.Limit), nil
+}
+
+// CreateQuizComment é a lógica de negócios para criar um comentário num quiz.
+func (u *quizUsecase) CreateQuizComment(ctx context.Context, quizIDStr string, req models.CreateCommentRequest) (*models.QAComment, error) {
+	quizID, err := strconv.Atoi(quizIDStr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: quiz_id inválido", ErrInvalidInput)
+	}
+	if req.Corpo == "" {
+		return nil, fmt.Errorf("%w: 'corpo' não pode estar em branco", ErrInvalidInput)
+	}
+
+	created, err := u.commentRepo.CreateForQuiz(ctx, quizID, req)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: quiz ou comentário-pai não encontrado", ErrNotFound)
+		}
+		return nil, fmt.Errorf("falha ao criar comentário do quiz no usecase: %w", err)
+	}
+	return created, nil
+}
+
+// DeleteQuizComment é a lógica de negócios para apagar um comentário de um
+// quiz: confirma que o comentário pertence mesmo a esse quiz (senão trata-se
+// como inexistente nesse caminho aninhado) e que quem pede é o autor ou um
+// admin.
+func (u *quizUsecase) DeleteQuizComment(ctx context.Context, quizIDStr, commentIDStr string, actorUserID int, actorIsAdmin bool) error {
+	quizID, err := strconv.Atoi(quizIDStr)
+	if err != nil {
+		return fmt.Errorf("%w: quiz_id inválido", ErrInvalidInput)
+	}
+	commentID, err := strconv.Atoi(commentIDStr)
+	if err != nil {
+		return fmt.Errorf("%w: comment_id inválido", ErrInvalidInput)
+	}
+
+	existing, err := u.commentRepo.Get(ctx, commentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: comentário com id %d não encontrado", ErrNotFound, commentID)
+		}
+		return fmt.Errorf("falha ao buscar comentário no usecase: %w", err)
+	}
+	if existing.QuizzID == nil || *existing.QuizzID != quizID {
+		return fmt.Errorf("%w: comentário com id %d não encontrado", ErrNotFound, commentID)
+	}
+	if !actorIsAdmin && existing.UtilizadorID != actorUserID {
+		return fmt.Errorf("%w: só o autor do comentário ou um admin pode apagá-lo", ErrForbidden)
+	}
+
+	if err := u.commentRepo.Delete(ctx, commentID); err != nil {
+		return fmt.Errorf("falha ao apagar comentário no usecase: %w", err)
+	}
+	return nil
+}
+
+// ExportQuizComments é a lógica de negócios do export.json: devolve todos os
+// comentários de um quiz de uma vez, sem paginação, para revisão offline.
+func (u *quizUsecase) ExportQuizComments(ctx context.Context, quizIDStr string) ([]models.QAComment, error) {
+	quizID, err := strconv.Atoi(quizIDStr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: quiz_id inválido", ErrInvalidInput)
+	}
+
+	var all []models.QAComment
+	cursor := 0
+	for {
+		page := models.CommentCursorPage{Limit: maxCommentExportPageSize, Cursor: cursor}
+		items, err := u.commentRepo.ListByQuiz(ctx, quizID, page)
+		if err != nil {
+			return nil, fmt.Errorf("falha ao exportar comentários do quiz no usecase: %w", err)
+		}
+		all = append(all, items...)
+		if len(items) < maxCommentExportPageSize {
+			break
+		}
+		cursor = items[len(items)-1].ID
+	}
+	return all, nil
+}
+
+// checkSubmissionOwnership confirma que actorUserID é o dono da submissão
+// (ou que actorIsAdmin), antes de expor os comentários de QA de uma
+// submissão: ao contrário dos de um quiz, estes são privados porque a
+// submissão subjacente também é (ver GetSubmissionDetails e o comentário em
+// router.go). Devolve ErrNotFound, não ErrForbidden, quando a submissão não
+// é do actor, para não confirmar a um atacante que o id existe.
+func (u *quizUsecase) checkSubmissionOwnership(ctx context.Context, submissionID, actorUserID int, actorIsAdmin bool) error {
+	if actorIsAdmin {
+		return nil
+	}
+	ownerID, err := u.submissionRepo.GetSubmissionOwner(ctx, submissionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: submissão com id %d não encontrada", ErrNotFound, submissionID)
+		}
+		return fmt.Errorf("falha ao verificar dono da submissão no usecase: %w", err)
+	}
+	if ownerID != actorUserID {
+		return fmt.Errorf("%w: submissão com id %d não encontrada", ErrNotFound, submissionID)
+	}
+	return nil
+}
+
+// ListSubmissionComments é o equivalente a ListQuizComments para comentários
+// de uma submissão, mas só devolve algo ao dono da submissão (ou a um
+// admin) — ver checkSubmissionOwnership.
+func (u *quizUsecase) ListSubmissionComments(ctx context.Context, submissionIDStr string, page models.CommentCursorPage, actorUserID int, actorIsAdmin bool) (models.CommentPage, error) {
+	submissionID, err := strconv.Atoi(submissionIDStr)
+	if err != nil {
+		return models.CommentPage{}, fmt.Errorf("%w: submission_id inválido", ErrInvalidInput)
+	}
+	if err := u.checkSubmissionOwnership(ctx, submissionID, actorUserID, actorIsAdmin); err != nil {
+		return models.CommentPage{}, err
+	}
+	page.Limit = store.NormalizeCursorLimit(page.Limit)
+
+	items, err := u.commentRepo.ListBySubmission(ctx, submissionID, page)
+	if err != nil {
+		return models.CommentPage{}, fmt.Errorf("falha ao listar comentários da submissão no usecase: %w", err)
+	}
+	return buildCommentPage(items, page.Limit), nil
+}
+
+// CreateSubmissionComment é o equivalente a CreateQuizComment para
+// comentários de uma submissão, mas só permite escrever ao dono da
+// submissão (ou a um admin) — ver checkSubmissionOwnership.
+func (u *quizUsecase) CreateSubmissionComment(ctx context.Context, submissionIDStr string, req models.CreateCommentRequest, actorUserID int, actorIsAdmin bool) (*models.QAComment, error) {
+	submissionID, err := strconv.Atoi(submissionIDStr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: submission_id inválido", ErrInvalidInput)
+	}
+	if req.Corpo == "" {
+		return nil, fmt.Errorf("%w: 'corpo' não pode estar em branco", ErrInvalidInput)
+	}
+	if err := u.checkSubmissionOwnership(ctx, submissionID, actorUserID, actorIsAdmin); err != nil {
+		return nil, err
+	}
+
+	created, err := u.commentRepo.CreateForSubmission(ctx, submissionID, req)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: submissão ou comentário-pai não encontrado", ErrNotFound)
+		}
+		return nil, fmt.Errorf("falha ao criar comentário da submissão no usecase: %w", err)
+	}
+	return created, nil
+}
+
+// DeleteSubmissionComment é o equivalente a DeleteQuizComment para
+// comentários de uma submissão.
+func (u *quizUsecase) DeleteSubmissionComment(ctx context.Context, submissionIDStr, commentIDStr string, actorUserID int, actorIsAdmin bool) error {
+	submissionID, err := strconv.Atoi(submissionIDStr)
+	if err != nil {
+		return fmt.Errorf("%w: submission_id inválido", ErrInvalidInput)
+	}
+	commentID, err := strconv.Atoi(commentIDStr)
+	if err != nil {
+		return fmt.Errorf("%w: comment_id inválido", ErrInvalidInput)
+	}
+
+	existing, err := u.commentRepo.Get(ctx, commentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: comentário com id %d não encontrado", ErrNotFound, commentID)
+		}
+		return fmt.Errorf("falha ao buscar comentário no usecase: %w", err)
+	}
+	if existing.SubmissaoID == nil || *existing.SubmissaoID != submissionID {
+		return fmt.Errorf("%w: comentário com id %d não encontrado", ErrNotFound, commentID)
+	}
+	if !actorIsAdmin && existing.UtilizadorID != actorUserID {
+		return fmt.Errorf("%w: só o autor do comentário ou um admin pode apagá-lo", ErrForbidden)
+	}
+
+	if err := u.commentRepo.Delete(ctx, commentID); err != nil {
+		return fmt.Errorf("falha ao apagar comentário no usecase: %w", err)
+	}
+	return nil
+}
+
+// maxCommentExportPageSize é o tamanho de página usado internamente por
+// ExportQuizComments para percorrer todos os comentários em lotes, em vez de
+// confiar no teto normal de store.NormalizeCursorLimit (pensado para
+// respostas a clientes, não para uma exportação completa).
+const maxCommentExportPageSize = 200
+
+// buildCommentPage monta um models.CommentPage a partir dos itens devolvidos
+// pelo repositório: se a página veio cheia (len(items) == limit), pode haver
+// mais, então NextCursor aponta para o último id devolvido.
+func buildCommentPage(items []models.QAComment, limit int) models.CommentPage {
+	page := models.CommentPage{Items: items}
+	if len(items) == limit {
+		page.NextCursor = items[len(items)-1].ID
+	}
+	return page
+}
+
+// CheckHealth verifica o estado das dependências (DB e LLM)
+func (u *quizUsecase) CheckHealth(ctx context.Context) models.HealthResponse {
+	response := models.HealthResponse{
+		OverallStatus: models.StatusUp,
+		Dependencies:  make(map[string]models.HealthStatus),
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if err := u.db.Ping(checkCtx); err != nil {
+		logging.FromContext(ctx).Error("health check: falha ao pingar DB", "error", err)
+		response.Dependencies["database"] = models.StatusDown
+		response.OverallStatus = models.StatusDown
+	} else {
+		response.Dependencies["database"] = models.StatusUp
+	}
+	metrics.SetDependencyUp("database", response.Dependencies["database"] == models.StatusUp)
+
+	if err := u.llmClient.Ping(checkCtx); err != nil {
+		logging.FromContext(ctx).Warn("health check: falha ao pingar LLM", "error", err)
+		response.Dependencies["llm"] = models.StatusDown
+	} else {
+		response.Dependencies["llm"] = models.StatusUp
+	}
+	if resilient, ok := u.llmClient.(*llm.ResilientProvider); ok && resilient.State() != llm.CircuitClosed {
+		response.Dependencies["llm"] = models.StatusDegraded
+	}
+	metrics.SetDependencyUp("llm", response.Dependencies["llm"] == models.StatusUp)
+	return response
+}
@@ -0,0 +1,487 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"quizz-core/internal/llm"
+	"quizz-core/internal/models"
+	"quizz-core/internal/repository/submission"
+)
+
+func TestCreateQuiz(t *testing.T) {
+	tests := []struct {
+		name        string
+		generateErr error
+		wantErr     error
+	}{
+		{name: "sucesso"},
+		{name: "circuit breaker aberto", generateErr: llm.ErrCircuitOpen, wantErr: ErrUnavailable},
+		{name: "erro genérico do provider", generateErr: errors.New("boom")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			llmProvider := &mockLLMProvider{
+				generateQuizFn: func(ctx context.Context, prompt string) (string, error) {
+					if tt.generateErr != nil {
+						return "", tt.generateErr
+					}
+					return `{"title":"Biologia","questions":[]}`, nil
+				},
+			}
+			uc := NewQuizUsecase(nil, nil, nil, nil, nil, nil, llmProvider)
+
+			resp, err := uc.CreateQuiz(context.Background(), models.CreateQuizRequest{UserID: "1", Theme: "Biologia"})
+
+			if tt.generateErr == nil {
+				if err != nil {
+					t.Fatalf("esperava sucesso, obteve erro: %v", err)
+				}
+				if resp.RawLLMJson == "" {
+					t.Fatal("esperava RawLLMJson preenchido")
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatal("esperava erro, obteve nil")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("esperava errors.Is(err, %v), obteve %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSubmitAnswers(t *testing.T) {
+	answerMap := map[string]submission.QuestionAnswerInfo{
+		"1": {
+			QuestionID:        1,
+			Assunto:           "mitocôndrias",
+			CorrectOptionText: "Produção de energia",
+			CorrectOptionID:   10,
+			OptionsMap:        map[string]int{"Produção de energia": 10, "Fotossíntese": 11},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		req     models.SubmissionRequest
+		repo    *mockSubmissionRepo
+		wantErr error
+	}{
+		{
+			name: "quiz_id inválido",
+			req:  models.SubmissionRequest{QuizID: "abc", UserID: "1"},
+			repo: &mockSubmissionRepo{},
+			wantErr: ErrInvalidInput,
+		},
+		{
+			name: "quiz não encontrado",
+			req:  models.SubmissionRequest{QuizID: "99", UserID: "1"},
+			repo: &mockSubmissionRepo{
+				getQuizAnswersFn: func(ctx context.Context, quizID int) (map[string]submission.QuestionAnswerInfo, error) {
+					return map[string]submission.QuestionAnswerInfo{}, nil
+				},
+			},
+			wantErr: ErrNotFound,
+		},
+		{
+			name: "sucesso com resposta correta",
+			req: models.SubmissionRequest{
+				QuizID: "1", UserID: "1",
+				Answers: []models.UserAnswer{{QuestionID: "1", SelectedOption: "Produção de energia"}},
+			},
+			repo: &mockSubmissionRepo{
+				getQuizAnswersFn: func(ctx context.Context, quizID int) (map[string]submission.QuestionAnswerInfo, error) {
+					return answerMap, nil
+				},
+				saveSubmissionStatsFn: func(ctx context.Context, sub models.Submissao, dadas []models.RespostaDada, difs []models.Dificuldade, idempotency *models.IdempotencyInfo) (*models.Submissao, error) {
+					return &models.Submissao{ID: 42, Pontuacao: sub.Pontuacao}, nil
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := NewQuizUsecase(nil, tt.repo, nil, nil, nil, nil, nil)
+			resp, err := uc.SubmitAnswers(context.Background(), tt.req)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("esperava errors.Is(err, %v), obteve %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("esperava sucesso, obteve erro: %v", err)
+			}
+			if resp.CorrectCount != 1 || resp.TotalCount != 1 {
+				t.Fatalf("esperava 1/1 acertos, obteve %d/%d", resp.CorrectCount, resp.TotalCount)
+			}
+		})
+	}
+}
+
+func TestSubmitAnswersIdempotency(t *testing.T) {
+	answerMap := map[string]submission.QuestionAnswerInfo{
+		"1": {
+			QuestionID:        1,
+			Assunto:           "mitocôndrias",
+			CorrectOptionText: "Produção de energia",
+			CorrectOptionID:   10,
+			OptionsMap:        map[string]int{"Produção de energia": 10, "Fotossíntese": 11},
+		},
+	}
+	req := models.SubmissionRequest{
+		QuizID: "1", UserID: "1",
+		Answers:        []models.UserAnswer{{QuestionID: "1", SelectedOption: "Produção de energia"}},
+		IdempotencyKey: "retry-1",
+		RequestHash:    "hash-a",
+	}
+
+	t.Run("replay com o mesmo hash devolve a resposta original", func(t *testing.T) {
+		repo := &mockSubmissionRepo{
+			getIdempotencyRecordFn: func(ctx context.Context, userID int, key string) (*models.IdempotencyRecord, error) {
+				return &models.IdempotencyRecord{SubmissaoID: 42, RequestHash: "hash-a"}, nil
+			},
+			getSubmissionSummaryFn: func(ctx context.Context, submissionID int) (*models.SubmissionResponse, error) {
+				return &models.SubmissionResponse{SubmissionID: submissionID, CorrectCount: 1, TotalCount: 1}, nil
+			},
+		}
+		uc := NewQuizUsecase(nil, repo, nil, nil, nil, nil, nil)
+
+		resp, err := uc.SubmitAnswers(context.Background(), req)
+		if err != nil {
+			t.Fatalf("esperava sucesso, obteve erro: %v", err)
+		}
+		if resp.SubmissionID != 42 {
+			t.Fatalf("esperava a submissão original (42), obteve %d", resp.SubmissionID)
+		}
+	})
+
+	t.Run("mesma chave com hash diferente é rejeitada", func(t *testing.T) {
+		repo := &mockSubmissionRepo{
+			getIdempotencyRecordFn: func(ctx context.Context, userID int, key string) (*models.IdempotencyRecord, error) {
+				return &models.IdempotencyRecord{SubmissaoID: 42, RequestHash: "hash-diferente"}, nil
+			},
+		}
+		uc := NewQuizUsecase(nil, repo, nil, nil, nil, nil, nil)
+
+		_, err := uc.SubmitAnswers(context.Background(), req)
+		if !errors.Is(err, ErrInvalidInput) {
+			t.Fatalf("esperava errors.Is(err, ErrInvalidInput), obteve %v", err)
+		}
+	})
+
+	t.Run("primeira submissão com a chave processa e grava normalmente", func(t *testing.T) {
+		repo := &mockSubmissionRepo{
+			getQuizAnswersFn: func(ctx context.Context, quizID int) (map[string]submission.QuestionAnswerInfo, error) {
+				return answerMap, nil
+			},
+			saveSubmissionStatsFn: func(ctx context.Context, sub models.Submissao, dadas []models.RespostaDada, difs []models.Dificuldade, idempotency *models.IdempotencyInfo) (*models.Submissao, error) {
+				if idempotency == nil || idempotency.Key != req.IdempotencyKey {
+					t.Fatalf("esperava a idempotency key %q propagada para SaveSubmissionStats, obteve %+v", req.IdempotencyKey, idempotency)
+				}
+				return &models.Submissao{ID: 42, Pontuacao: sub.Pontuacao}, nil
+			},
+		}
+		uc := NewQuizUsecase(nil, repo, nil, nil, nil, nil, nil)
+
+		resp, err := uc.SubmitAnswers(context.Background(), req)
+		if err != nil {
+			t.Fatalf("esperava sucesso, obteve erro: %v", err)
+		}
+		if resp.SubmissionID != 42 {
+			t.Fatalf("esperava submissão 42, obteve %d", resp.SubmissionID)
+		}
+	})
+
+	t.Run("conflito de idempotência concorrente devolve a resposta da submissão vencedora", func(t *testing.T) {
+		repo := &mockSubmissionRepo{
+			getQuizAnswersFn: func(ctx context.Context, quizID int) (map[string]submission.QuestionAnswerInfo, error) {
+				return answerMap, nil
+			},
+			saveSubmissionStatsFn: func(ctx context.Context, sub models.Submissao, dadas []models.RespostaDada, difs []models.Dificuldade, idempotency *models.IdempotencyInfo) (*models.Submissao, error) {
+				return nil, submission.ErrIdempotencyConflict
+			},
+			getIdempotencyRecordFn: func(ctx context.Context, userID int, key string) (*models.IdempotencyRecord, error) {
+				return &models.IdempotencyRecord{SubmissaoID: 99, RequestHash: req.RequestHash}, nil
+			},
+			getSubmissionSummaryFn: func(ctx context.Context, submissionID int) (*models.SubmissionResponse, error) {
+				return &models.SubmissionResponse{SubmissionID: submissionID, CorrectCount: 1, TotalCount: 1}, nil
+			},
+		}
+		uc := NewQuizUsecase(nil, repo, nil, nil, nil, nil, nil)
+
+		resp, err := uc.SubmitAnswers(context.Background(), req)
+		if err != nil {
+			t.Fatalf("esperava sucesso (fallback para a submissão vencedora), obteve erro: %v", err)
+		}
+		if resp.SubmissionID != 99 {
+			t.Fatalf("esperava a submissão da vencedora da corrida (99), obteve %d", resp.SubmissionID)
+		}
+	})
+
+	t.Run("conflito de idempotência com hash diferente é rejeitado mesmo após a corrida", func(t *testing.T) {
+		repo := &mockSubmissionRepo{
+			getQuizAnswersFn: func(ctx context.Context, quizID int) (map[string]submission.QuestionAnswerInfo, error) {
+				return answerMap, nil
+			},
+			saveSubmissionStatsFn: func(ctx context.Context, sub models.Submissao, dadas []models.RespostaDada, difs []models.Dificuldade, idempotency *models.IdempotencyInfo) (*models.Submissao, error) {
+				return nil, submission.ErrIdempotencyConflict
+			},
+			getIdempotencyRecordFn: func(ctx context.Context, userID int, key string) (*models.IdempotencyRecord, error) {
+				return &models.IdempotencyRecord{SubmissaoID: 99, RequestHash: "hash-diferente"}, nil
+			},
+		}
+		uc := NewQuizUsecase(nil, repo, nil, nil, nil, nil, nil)
+
+		_, err := uc.SubmitAnswers(context.Background(), req)
+		if !errors.Is(err, ErrInvalidInput) {
+			t.Fatalf("esperava errors.Is(err, ErrInvalidInput), obteve %v", err)
+		}
+	})
+}
+
+func TestSaveDraft(t *testing.T) {
+	answerMap := map[string]submission.QuestionAnswerInfo{
+		"1": {
+			QuestionID: 1,
+			Assunto:    "mitocôndrias",
+			OptionsMap: map[string]int{"Produção de energia": 10, "Fotossíntese": 11},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		req     models.DraftRequest
+		repo    *mockSubmissionRepo
+		wantErr error
+	}{
+		{
+			name:    "quiz_id inválido",
+			req:     models.DraftRequest{QuizID: "abc", UserID: "1"},
+			repo:    &mockSubmissionRepo{},
+			wantErr: ErrInvalidInput,
+		},
+		{
+			name:    "sem respostas",
+			req:     models.DraftRequest{QuizID: "1", UserID: "1"},
+			repo:    &mockSubmissionRepo{},
+			wantErr: ErrInvalidInput,
+		},
+		{
+			name: "sucesso",
+			req: models.DraftRequest{
+				QuizID: "1", UserID: "1",
+				Answers: []models.UserAnswer{{QuestionID: "1", SelectedOption: "Fotossíntese"}},
+			},
+			repo: &mockSubmissionRepo{
+				getQuizAnswersFn: func(ctx context.Context, quizID int) (map[string]submission.QuestionAnswerInfo, error) {
+					return answerMap, nil
+				},
+				saveDraftAnswersFn: func(ctx context.Context, userID, quizID int, answers map[int]int) error {
+					if answers[1] != 11 {
+						t.Fatalf("esperava resposta_id 11 para a pergunta 1, obteve %d", answers[1])
+					}
+					return nil
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := NewQuizUsecase(nil, tt.repo, nil, nil, nil, nil, nil)
+			err := uc.SaveDraft(context.Background(), tt.req)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("esperava errors.Is(err, %v), obteve %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("esperava sucesso, obteve erro: %v", err)
+			}
+		})
+	}
+}
+
+func TestSubmissionCommentsOwnership(t *testing.T) {
+	submissionRepo := &mockSubmissionRepo{
+		getSubmissionOwnerFn: func(ctx context.Context, submissionID int) (int, error) {
+			if submissionID == 99 {
+				return 0, sql.ErrNoRows
+			}
+			return 7, nil
+		},
+	}
+	commentRepo := &mockCommentRepo{
+		listBySubmissionFn: func(ctx context.Context, submissionID int, page models.CommentCursorPage) ([]models.QAComment, error) {
+			return []models.QAComment{{ID: 1}}, nil
+		},
+		createForSubmissionFn: func(ctx context.Context, submissionID int, req models.CreateCommentRequest) (*models.QAComment, error) {
+			return &models.QAComment{ID: 2}, nil
+		},
+	}
+	uc := NewQuizUsecase(nil, submissionRepo, nil, nil, commentRepo, nil, nil)
+
+	tests := []struct {
+		name         string
+		actorUserID  int
+		actorIsAdmin bool
+		wantErr      error
+	}{
+		{name: "dono da submissão vê os comentários", actorUserID: 7},
+		{name: "outro utilizador é tratado como se a submissão não existisse", actorUserID: 8, wantErr: ErrNotFound},
+		{name: "admin vê os comentários de qualquer submissão", actorUserID: 8, actorIsAdmin: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := uc.ListSubmissionComments(context.Background(), "1", models.CommentCursorPage{}, tt.actorUserID, tt.actorIsAdmin)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("esperava errors.Is(err, %v), obteve %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("esperava sucesso, obteve erro: %v", err)
+			}
+
+			if _, err := uc.CreateSubmissionComment(context.Background(), "1", models.CreateCommentRequest{Corpo: "oi"}, tt.actorUserID, tt.actorIsAdmin); err != nil {
+				t.Fatalf("esperava sucesso ao criar, obteve erro: %v", err)
+			}
+		})
+	}
+
+	t.Run("submissão inexistente devolve ErrNotFound", func(t *testing.T) {
+		_, err := uc.ListSubmissionComments(context.Background(), "99", models.CommentCursorPage{}, 7, false)
+		if !errors.Is(err, ErrNotFound) {
+			t.Fatalf("esperava errors.Is(err, ErrNotFound), obteve %v", err)
+		}
+	})
+}
+
+func TestGetSubmissionDetailsOwnership(t *testing.T) {
+	submissionRepo := &mockSubmissionRepo{
+		getSubmissionOwnerFn: func(ctx context.Context, submissionID int) (int, error) {
+			return 7, nil
+		},
+		getSubmissionDetailsFn: func(ctx context.Context, submissionID int) (*models.SubmissionDetailResponse, error) {
+			return &models.SubmissionDetailResponse{SubmissionID: submissionID}, nil
+		},
+	}
+	uc := NewQuizUsecase(nil, submissionRepo, nil, nil, nil, nil, nil)
+
+	tests := []struct {
+		name         string
+		actorUserID  int
+		actorIsAdmin bool
+		wantErr      error
+	}{
+		{name: "dono da submissão vê os detalhes", actorUserID: 7},
+		{name: "outro utilizador é tratado como se a submissão não existisse", actorUserID: 8, wantErr: ErrNotFound},
+		{name: "admin vê os detalhes de qualquer submissão", actorUserID: 8, actorIsAdmin: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := uc.GetSubmissionDetails(context.Background(), "1", tt.actorUserID, tt.actorIsAdmin)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("esperava errors.Is(err, %v), obteve %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("esperava sucesso, obteve erro: %v", err)
+			}
+		})
+	}
+}
+
+func TestDeactivateQuiz(t *testing.T) {
+	tests := []struct {
+		name    string
+		quizID  string
+		repo    *mockQuizRepo
+		wantErr error
+	}{
+		{
+			name:    "quiz_id inválido",
+			quizID:  "abc",
+			repo:    &mockQuizRepo{},
+			wantErr: ErrInvalidInput,
+		},
+		{
+			name:   "quiz não encontrado",
+			quizID: "5",
+			repo: &mockQuizRepo{
+				deactivateQuizFn: func(ctx context.Context, quizID int) (int64, error) {
+					return 0, nil
+				},
+			},
+			wantErr: ErrNotFound,
+		},
+		{
+			name:   "sucesso",
+			quizID: "5",
+			repo: &mockQuizRepo{
+				deactivateQuizFn: func(ctx context.Context, quizID int) (int64, error) {
+					return 1, nil
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := NewQuizUsecase(tt.repo, nil, nil, nil, nil, nil, nil)
+			err := uc.DeactivateQuiz(context.Background(), tt.quizID)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("esperava errors.Is(err, %v), obteve %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("esperava sucesso, obteve erro: %v", err)
+			}
+		})
+	}
+}
+
+func TestCheckHealth(t *testing.T) {
+	tests := []struct {
+		name       string
+		dbErr      error
+		llmErr     error
+		wantStatus models.HealthStatus
+	}{
+		{name: "tudo ok", wantStatus: models.StatusUp},
+		{name: "db fora do ar", dbErr: errors.New("connection refused"), wantStatus: models.StatusDown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pinger := &mockPinger{pingFn: func(ctx context.Context) error { return tt.dbErr }}
+			llmProvider := &mockLLMProvider{pingFn: func(ctx context.Context) error { return tt.llmErr }}
+			uc := NewQuizUsecase(nil, nil, nil, nil, nil, pinger, llmProvider)
+
+			health := uc.CheckHealth(context.Background())
+
+			if health.OverallStatus != tt.wantStatus {
+				t.Fatalf("esperava status %v, obteve %v", tt.wantStatus, health.OverallStatus)
+			}
+		})
+	}
+}